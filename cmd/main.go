@@ -1,18 +1,37 @@
+// Command gock3 checks the scripts of Crusader Kings III mods.
+//
+// It exits with:
+//
+//	0 when it ran to the end, without more than --max-errors (0 by default) problems at or above --fail-on (error by default)
+//	1 when it found more of them, or files that fmt --check would change
+//	2 when it could not run, because of wrong arguments or files it could not read or write
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/unLomTrois/gock3/internal/app/cli"
+	"github.com/unLomTrois/gock3/pkg/report"
 )
 
 func main() {
 	log.SetFlags(log.Lshortfile)
 
+	// Debugging aid: show which check reported each diagnostic
+	if os.Getenv("GOCK3_TRACE_CALLERS") != "" {
+		report.TraceCallers(true)
+	}
+
 	if err := root(os.Args); err != nil {
-		log.Fatalf("Error: %v", err)
+		if errors.Is(err, cli.ErrProblemsFound) {
+			log.Println(err)
+			os.Exit(1)
+		}
+		log.Printf("Error: %v", err)
+		os.Exit(2)
 	}
 }
 
@@ -20,12 +39,13 @@ func root(args []string) error {
 	commands := []cli.Command{
 		cli.NewParseCommand(),
 		cli.NewProjectCommand(),
+		cli.NewFmtCommand(),
+		cli.NewRulesCommand(),
 	}
 
 	if len(args) < 2 {
-		fmt.Println("No command provided")
 		printHelp(commands)
-		return nil
+		return fmt.Errorf("no command provided")
 	}
 
 	subcommand := args[1]
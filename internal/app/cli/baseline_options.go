@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/baseline"
+)
+
+// baselineOptions holds the flags that record the diagnostics of a command, or hide the recorded ones
+type baselineOptions struct {
+	baseline      string
+	writeBaseline string
+}
+
+func newBaselineOptions(flagset *flag.FlagSet, example string) *baselineOptions {
+	options := &baselineOptions{}
+
+	flagset.StringVar(
+		&options.writeBaseline,
+		"write-baseline",
+		"",
+		fmt.Sprintf("Record the diagnostics in a baseline file, to be passed to --baseline later\n%s --write-baseline baseline.json", example),
+	)
+
+	flagset.StringVar(
+		&options.baseline,
+		"baseline",
+		"",
+		fmt.Sprintf("Report only the diagnostics that are not in the baseline file, and list the ones that are fixed\n%s --baseline baseline.json", example),
+	)
+
+	return options
+}
+
+// validate checks the flags before the command does any work
+func (o *baselineOptions) validate() error {
+	if o.baseline != "" && o.writeBaseline != "" {
+		return fmt.Errorf("--baseline and --write-baseline can't be used together")
+	}
+	return nil
+}
+
+// apply writes the baseline when --write-baseline is given, and returns the diagnostics to report.
+// With --baseline only the diagnostics missing from the baseline are returned.
+// Paths are fingerprinted relative to the roots.
+func (o *baselineOptions) apply(items []*report.DiagnosticItem, roots ...string) ([]*report.DiagnosticItem, error) {
+	fingerprinter := baseline.NewFingerprinter(roots...)
+
+	if o.writeBaseline != "" {
+		known := fingerprinter.New(items)
+		if err := known.Write(o.writeBaseline); err != nil {
+			return nil, err
+		}
+		log.Printf("Wrote %d diagnostics to the baseline %s", len(items), o.writeBaseline)
+		return items, nil
+	}
+
+	if o.baseline == "" {
+		return items, nil
+	}
+
+	known, err := baseline.Read(o.baseline)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh, fixed := fingerprinter.Filter(known, items)
+	log.Printf("%d diagnostics are in the baseline, %d are new", len(items)-len(fresh), len(fresh))
+
+	if len(fixed) > 0 {
+		log.Printf("%d baseline entries are fixed, update the baseline with --write-baseline:", len(fixed))
+		for _, entry := range fixed {
+			if entry.Count > 1 {
+				log.Printf("  %s (%d times)", entry.Fingerprint, entry.Count)
+			} else {
+				log.Printf("  %s", entry.Fingerprint)
+			}
+		}
+	}
+
+	return fresh, nil
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report"
+)
+
+// Formats of the diagnostics written by the commands
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatSARIF  = "sarif"
+)
+
+var formats = []string{formatText, formatJSON, formatNDJSON, formatSARIF}
+
+// diagnosticsOutput holds the flags that choose how a command writes its diagnostics
+type diagnosticsOutput struct {
+	format string
+	output string
+	stdout io.Writer
+}
+
+func newDiagnosticsOutput(flagset *flag.FlagSet, example string) *diagnosticsOutput {
+	output := &diagnosticsOutput{stdout: os.Stdout}
+
+	flagset.StringVar(
+		&output.format,
+		"format",
+		formatText,
+		fmt.Sprintf("Format of the diagnostics: %s\n%s --format json", strings.Join(formats, ", "), example),
+	)
+
+	flagset.StringVar(
+		&output.output,
+		"output",
+		"",
+		fmt.Sprintf("Write the diagnostics to a file instead of the standard output\n%s --format sarif --output gock3.sarif", example),
+	)
+
+	return output
+}
+
+// validate checks the flags before the command does any work
+func (o *diagnosticsOutput) validate() error {
+	if !slices.Contains(formats, o.format) {
+		return fmt.Errorf("unknown format %q, expected one of %s", o.format, strings.Join(formats, ", "))
+	}
+	return nil
+}
+
+// write writes the diagnostics in the chosen format.
+// SARIF file URIs are relative to the first root that contains the file.
+func (o *diagnosticsOutput) write(items []*report.DiagnosticItem, roots ...report.SARIFRoot) error {
+	out := o.stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return fmt.Errorf("creating the output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch o.format {
+	case formatJSON:
+		return report.WriteJSON(out, items)
+	case formatNDJSON:
+		return report.WriteNDJSON(out, items)
+	case formatSARIF:
+		return report.WriteSARIF(out, items, roots...)
+	default:
+		sink := report.NewTerminalSink(out)
+		for _, item := range items {
+			sink.Report(item)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/unLomTrois/gock3/internal/app/fixer"
+	"github.com/unLomTrois/gock3/pkg/report"
+)
+
+// fixOptions holds the flags that make a command fix the problems it finds
+type fixOptions struct {
+	fix    bool
+	dryRun bool
+	stdout io.Writer
+}
+
+func newFixOptions(flagset *flag.FlagSet, example string) *fixOptions {
+	options := &fixOptions{stdout: os.Stdout}
+
+	flagset.BoolVar(
+		&options.fix,
+		"fix",
+		false,
+		fmt.Sprintf("Apply the fixes of the diagnostics to the files\n%s --fix", example),
+	)
+
+	flagset.BoolVar(
+		&options.dryRun,
+		"dry-run",
+		false,
+		fmt.Sprintf("With --fix, print the fixes as a unified diff instead of applying them\n%s --fix --dry-run", example),
+	)
+
+	return options
+}
+
+// validate checks the flags before the command does any work
+func (o *fixOptions) validate() error {
+	if o.dryRun && !o.fix {
+		return fmt.Errorf("--dry-run only works together with --fix")
+	}
+	return nil
+}
+
+// apply fixes what it can when --fix is given, and returns the diagnostics that are left.
+// With --dry-run the changes are printed instead of written. Once files are written, the diagnostics
+// that are left point into their old content, so they are the ones of analyze run again on the fixed files.
+func (o *fixOptions) apply(items []*report.DiagnosticItem, analyze func() ([]*report.DiagnosticItem, error)) ([]*report.DiagnosticItem, error) {
+	if !o.fix {
+		return items, nil
+	}
+
+	result, err := fixer.Apply(items)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range result.Files {
+		if o.dryRun {
+			fmt.Fprint(o.stdout, file.Diff())
+			continue
+		}
+		if err := file.Write(); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.dryRun {
+		log.Printf("%d problems can be fixed in %d files", len(result.Fixed), len(result.Files))
+		return result.Remaining, nil
+	}
+
+	log.Printf("Fixed %d problems in %d files", len(result.Fixed), len(result.Files))
+	if len(result.Files) == 0 {
+		return result.Remaining, nil
+	}
+	return analyze()
+}
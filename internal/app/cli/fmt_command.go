@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unLomTrois/gock3/internal/app/diff"
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/formatter"
+)
+
+type FmtCommand struct {
+	flagset *flag.FlagSet
+	out     io.Writer
+	write   bool
+	check   bool
+	diff    bool
+}
+
+func NewFmtCommand() *FmtCommand {
+	command := &FmtCommand{
+		flagset: flag.NewFlagSet("fmt", flag.ExitOnError),
+		out:     os.Stdout,
+	}
+
+	command.flagset.BoolVar(
+		&command.write,
+		"write",
+		false,
+		"Write the result to the files instead of printing it\ngock3 fmt --write events/",
+	)
+
+	command.flagset.BoolVar(
+		&command.check,
+		"check",
+		false,
+		"List the files that are not formatted and fail if there are any\ngock3 fmt --check events/",
+	)
+
+	command.flagset.BoolVar(
+		&command.diff,
+		"diff",
+		false,
+		"Print the changes as a unified diff instead of the formatted files\ngock3 fmt --diff events/my_events.txt",
+	)
+
+	return command
+}
+
+func (command *FmtCommand) Name() string {
+	return command.flagset.Name()
+}
+
+func (command *FmtCommand) Description() string {
+	return "Format script files in the canonical layout"
+}
+
+// Run formats the files and directories given in args.
+// Flags may come before, after or between the paths.
+func (command *FmtCommand) Run(args []string) error {
+	paths, err := command.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("not enough arguments")
+	}
+
+	filePaths, err := collectScriptFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	var failed, unformatted []string
+	for _, path := range filePaths {
+		changed, err := command.format(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = append(failed, path)
+			continue
+		}
+		if changed {
+			unformatted = append(unformatted, path)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to format %d files", len(failed))
+	}
+
+	if command.check && len(unformatted) > 0 {
+		return fmt.Errorf("%w: %d files are not formatted", ErrProblemsFound, len(unformatted))
+	}
+
+	return nil
+}
+
+func (command *FmtCommand) parseArgs(args []string) ([]string, error) {
+	var paths []string
+
+	for {
+		if err := command.flagset.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = command.flagset.Args()
+		if len(args) == 0 {
+			return paths, nil
+		}
+
+		paths = append(paths, args[0])
+		args = args[1:]
+	}
+}
+
+// format formats a single file and reports whether it changed
+func (command *FmtCommand) format(path string) (bool, error) {
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		return false, fmt.Errorf("reading file: %w", err)
+	}
+	content, err := entry.ReadFile()
+	if err != nil {
+		return false, fmt.Errorf("reading file: %w", err)
+	}
+
+	formatted, err := formatter.Source(entry, content)
+	if err != nil {
+		return false, err
+	}
+
+	changed := !bytes.Equal(content, formatted)
+
+	if command.check {
+		if changed {
+			fmt.Fprintln(command.out, path)
+		}
+		return changed, nil
+	}
+
+	if command.diff {
+		fmt.Fprint(command.out, diff.Unified(path, path, string(content), string(formatted)))
+	}
+
+	if command.write {
+		if changed {
+			if err := writeFilePreservingMode(path, formatted); err != nil {
+				return false, err
+			}
+		}
+		return changed, nil
+	}
+
+	if !command.diff {
+		command.out.Write(formatted)
+	}
+
+	return changed, nil
+}
+
+// collectScriptFiles expands directories to the .txt files inside them
+func collectScriptFiles(paths []string) ([]string, error) {
+	var result []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			result = append(result, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(path), ".txt") {
+				result = append(result, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func writeFilePreservingMode(path string, content []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	return nil
+}
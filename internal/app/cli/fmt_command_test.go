@@ -0,0 +1,111 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/cli"
+)
+
+func TestFmtCommand_Name(t *testing.T) {
+	cmd := cli.NewFmtCommand()
+	if cmd.Name() != "fmt" {
+		t.Errorf("FmtCommand.Name() = %v, want %v", cmd.Name(), "fmt")
+	}
+}
+
+func TestFmtCommand_MissingArguments(t *testing.T) {
+	cmd := cli.NewFmtCommand()
+	if err := cmd.Run([]string{"--check"}); err == nil {
+		t.Errorf("expected error for missing arguments, got nil")
+	}
+}
+
+// writeScripts writes the files into a temporary directory and returns it
+func writeScripts(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range scripts {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func readScript(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestFmtCommand_Write(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"events/a.txt":      "a={b=c}",
+		"events/b.txt":      "a = b\n",
+		"events/notes.md":   "a={b=c}",
+		"common/nested.txt": "x = {\ny = z\n}",
+	})
+
+	cmd := cli.NewFmtCommand()
+	if err := cmd.Run([]string{dir, "--write"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string]string{
+		"events/a.txt":      "a = {\n\tb = c\n}\n",
+		"events/b.txt":      "a = b\n",
+		"events/notes.md":   "a={b=c}",
+		"common/nested.txt": "x = {\n\ty = z\n}\n",
+	}
+	for name, content := range want {
+		if got := readScript(t, filepath.Join(dir, name)); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestFmtCommand_Check(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"formatted.txt":   "a = b\n",
+		"unformatted.txt": "a=b",
+	})
+
+	if err := cli.NewFmtCommand().Run([]string{"--check", filepath.Join(dir, "formatted.txt")}); err != nil {
+		t.Errorf("expected no error for a formatted file, got %v", err)
+	}
+
+	unformatted := filepath.Join(dir, "unformatted.txt")
+	if err := cli.NewFmtCommand().Run([]string{"--check", unformatted}); err == nil {
+		t.Errorf("expected error for an unformatted file, got nil")
+	}
+
+	if got := readScript(t, unformatted); got != "a=b" {
+		t.Errorf("--check changed the file to %q", got)
+	}
+}
+
+func TestFmtCommand_SyntaxError(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"broken.txt": "a = {\n",
+	})
+
+	path := filepath.Join(dir, "broken.txt")
+	if err := cli.NewFmtCommand().Run([]string{"--write", path}); err == nil {
+		t.Errorf("expected error for a file with syntax errors, got nil")
+	}
+
+	if got := readScript(t, path); got != "a = {\n" {
+		t.Errorf("the broken file was changed to %q", got)
+	}
+}
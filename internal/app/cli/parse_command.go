@@ -7,13 +7,21 @@ import (
 	"path/filepath"
 
 	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/internal/app/pdxfile"
 	"github.com/unLomTrois/gock3/internal/app/utils"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/suppress"
 )
 
 type ParseCommand struct {
-	flagset     *flag.FlagSet
-	astFilepath string
+	flagset      *flag.FlagSet
+	astFilepath  string
+	keepComments bool
+	diagnostics  *diagnosticsOutput
+	fixes        *fixOptions
+	threshold    *thresholdOptions
 }
 
 func NewParseCommand() *ParseCommand {
@@ -28,6 +36,17 @@ func NewParseCommand() *ParseCommand {
 		"Save the AST to a file\ngock3 parse file.txt --save-ast ast.json",
 	)
 
+	command.flagset.BoolVar(
+		&command.keepComments,
+		"comments",
+		false,
+		"Keep comments and attach them to the AST\ngock3 parse file.txt --comments --save-ast ast.json",
+	)
+
+	command.diagnostics = newDiagnosticsOutput(command.flagset, "gock3 parse file.txt")
+	command.fixes = newFixOptions(command.flagset, "gock3 parse file.txt")
+	command.threshold = newThresholdOptions(command.flagset, "gock3 parse file.txt")
+
 	return command
 }
 
@@ -51,6 +70,18 @@ func (command *ParseCommand) Run(args []string) error {
 		return err
 	}
 
+	if err := command.diagnostics.validate(); err != nil {
+		return err
+	}
+
+	if err := command.fixes.validate(); err != nil {
+		return err
+	}
+
+	if err := command.threshold.validate(); err != nil {
+		return err
+	}
+
 	filePath := args[0]
 	fullpath, err := utils.FileExists(filePath)
 	if err != nil {
@@ -68,26 +99,55 @@ func (command *ParseCommand) validateArgs(args []string) error {
 }
 
 func (command *ParseCommand) parse(fullpath string) error {
-	fileEntry := files.NewFileEntry(fullpath, files.FileKind(files.Mod))
+	ast, diagnostics, err := command.analyze(fullpath)
+	if err != nil {
+		return err
+	}
 
-	ast, err := pdxfile.ParseFile(fileEntry)
+	diagnostics, err = command.fixes.apply(diagnostics, func() ([]*report.DiagnosticItem, error) {
+		fixed, diagnostics, err := command.analyze(fullpath)
+		if err != nil {
+			return nil, err
+		}
+		ast = fixed
+		return diagnostics, nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if command.astFilepath == "" {
-		return nil
+	// Paths in SARIF are relative to the directory the command runs in
+	if err := command.diagnostics.write(diagnostics, report.SARIFRoot{ID: "SRCROOT", Path: "."}); err != nil {
+		return err
+	}
+
+	if command.astFilepath != "" {
+		if err := utils.SaveJSON(ast, command.astFilepath); err != nil {
+			return fmt.Errorf("failed to save AST: %w", err)
+		}
+
+		absPath, err := filepath.Abs(command.astFilepath)
+		if err != nil {
+			return err
+		}
+
+		log.Println("Saved parse tree to", absPath)
 	}
 
-	if err := utils.SaveJSON(ast, command.astFilepath); err != nil {
-		return fmt.Errorf("failed to save AST: %w", err)
+	return command.threshold.check(diagnostics)
+}
+
+// analyze parses the file and returns its tree and its diagnostics, without the suppressed ones
+func (command *ParseCommand) analyze(fullpath string) (*ast.AST, []*report.DiagnosticItem, error) {
+	fileEntry, err := files.OpenFileEntry(nil, nil, fullpath, files.Mod)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	absPath, err := filepath.Abs(command.astFilepath)
+	tree, diagnostics, err := pdxfile.ParseFileWithOptions(fileEntry, lexer.Options{KeepComments: command.keepComments})
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	log.Println("Saved parse tree to", absPath)
-	return nil
+	return tree, suppress.Apply(diagnostics, tree.Directives), nil
 }
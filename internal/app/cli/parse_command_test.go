@@ -2,12 +2,15 @@
 package cli_test
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/unLomTrois/gock3/internal/app/cli"
+	"github.com/unLomTrois/gock3/pkg/report"
 )
 
 // --------------------
@@ -129,3 +132,220 @@ func TestParseCommand_ValidFile_WithAstFlag(t *testing.T) {
 		t.Errorf("expected AST file to exist at %s, but it does not", astPath)
 	}
 }
+
+func TestParseCommand_SARIFOutput(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"broken.txt": "a = {\n\tb = c\n"})
+	sarifPath := filepath.Join(dir, "out.sarif")
+
+	cmd := cli.NewParseCommand()
+	if err := cmd.Run([]string{filepath.Join(dir, "broken.txt"), "--format", "sarif", "--output", sarifPath}); err != nil && !errors.Is(err, cli.ErrProblemsFound) {
+		t.Fatalf("expected only problems found, got %v", err)
+	}
+
+	content, err := os.ReadFile(sarifPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(content, &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 || log.Runs[0].Results[0].RuleID != "parse/unclosed-block" {
+		t.Errorf("unexpected SARIF log:\n%s", content)
+	}
+}
+
+func TestParseCommand_UnknownFormat(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"a.txt": "a = b"})
+
+	cmd := cli.NewParseCommand()
+	if err := cmd.Run([]string{filepath.Join(dir, "a.txt"), "--format", "xml"}); err == nil {
+		t.Errorf("expected error for an unknown format, got nil")
+	}
+}
+
+func TestParseCommand_JSONOutput(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"broken.txt": "a =\nb = c\n"})
+
+	tests := []struct {
+		format string
+		decode func(content []byte) (int, []string, error)
+	}{
+		{"json", func(content []byte) (int, []string, error) {
+			var document struct {
+				Version     int `json:"version"`
+				Diagnostics []struct {
+					Rule string `json:"rule"`
+				} `json:"diagnostics"`
+			}
+			err := json.Unmarshal(content, &document)
+			var rules []string
+			for _, diagnostic := range document.Diagnostics {
+				rules = append(rules, diagnostic.Rule)
+			}
+			return document.Version, rules, err
+		}},
+		{"ndjson", func(content []byte) (int, []string, error) {
+			var record struct {
+				Version int    `json:"version"`
+				Rule    string `json:"rule"`
+			}
+			err := json.Unmarshal(content, &record)
+			return record.Version, []string{record.Rule}, err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			outPath := filepath.Join(dir, "out."+tt.format)
+
+			cmd := cli.NewParseCommand()
+			if err := cmd.Run([]string{filepath.Join(dir, "broken.txt"), "--format", tt.format, "--output", outPath}); err != nil && !errors.Is(err, cli.ErrProblemsFound) {
+				t.Fatalf("expected only problems found, got %v", err)
+			}
+
+			content, err := os.ReadFile(outPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			version, rules, err := tt.decode(content)
+			if err != nil {
+				t.Fatalf("invalid %s: %v\n%s", tt.format, err, content)
+			}
+			if version != 1 || len(rules) != 1 || rules[0] != "parse/missing-value" {
+				t.Errorf("unexpected output:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestParseCommand_Fix(t *testing.T) {
+	const broken = "a == {\n\tb = c\n}\n"
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"fix", []string{"--fix"}, "a = {\n\tb = c\n}\n"},
+		{"dry run", []string{"--fix", "--dry-run"}, broken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeScripts(t, map[string]string{"broken.txt": broken})
+			path := filepath.Join(dir, "broken.txt")
+			outPath := filepath.Join(dir, "out.json")
+
+			args := append([]string{path, "--format", "json", "--output", outPath}, tt.args...)
+			if err := cli.NewParseCommand().Run(args); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if got := readScript(t, path); got != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+
+			// The fixed diagnostics are not reported
+			var document struct {
+				Diagnostics []json.RawMessage `json:"diagnostics"`
+			}
+			if err := json.Unmarshal([]byte(readScript(t, outPath)), &document); err != nil {
+				t.Fatal(err)
+			}
+			if len(document.Diagnostics) != 0 {
+				t.Errorf("got %d diagnostics, want none", len(document.Diagnostics))
+			}
+		})
+	}
+}
+
+func TestParseCommand_FixLocations(t *testing.T) {
+	// Removing the unused directive moves the missing value up a line
+	dir := writeScripts(t, map[string]string{"broken.txt": "# gock3:ignore parse/missing-value\na = b\nc =\n"})
+	path := filepath.Join(dir, "broken.txt")
+	outPath := filepath.Join(dir, "out.json")
+
+	args := []string{path, "--fix", "--format", "json", "--output", outPath, "--fail-on", "critical"}
+	if err := cli.NewParseCommand().Run(args); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got, want := readScript(t, path), "a = b\nc =\n"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+
+	var document struct {
+		Diagnostics []report.JSONDiagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal([]byte(readScript(t, outPath)), &document); err != nil {
+		t.Fatal(err)
+	}
+	if len(document.Diagnostics) != 1 {
+		t.Fatalf("got %d diagnostics, want 1", len(document.Diagnostics))
+	}
+	if got := document.Diagnostics[0]; got.Rule != "parse/missing-value" || got.Line != 2 {
+		t.Errorf("got %s at line %d, want parse/missing-value at line 2", got.Rule, got.Line)
+	}
+}
+
+func TestParseCommand_DryRunWithoutFix(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"a.txt": "a == { b = c }"})
+
+	if err := cli.NewParseCommand().Run([]string{filepath.Join(dir, "a.txt"), "--dry-run"}); err == nil {
+		t.Errorf("expected error for --dry-run without --fix, got nil")
+	}
+}
+
+func TestParseCommand_Threshold(t *testing.T) {
+	// A warning and an error
+	dir := writeScripts(t, map[string]string{"broken.txt": "a =\nb == { c = d }\n"})
+	path := filepath.Join(dir, "broken.txt")
+
+	tests := []struct {
+		name string
+		args []string
+		fail bool
+	}{
+		{"no flags", nil, true},
+		{"fail on warning", []string{"--fail-on", "warning"}, true},
+		{"fail on error", []string{"--fail-on", "error"}, true},
+		{"fail on critical", []string{"--fail-on", "critical"}, false},
+		{"errors allowed", []string{"--max-errors", "1"}, false},
+		{"too many errors", []string{"--max-errors", "0"}, true},
+		{"warnings allowed", []string{"--fail-on", "warning", "--max-errors", "2"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{path, "--format", "json", "--output", filepath.Join(dir, "out.json")}, tt.args...)
+			err := cli.NewParseCommand().Run(args)
+
+			if got := errors.Is(err, cli.ErrProblemsFound); got != tt.fail {
+				t.Errorf("Run() = %v, want failure %v", err, tt.fail)
+			}
+			if err != nil && !tt.fail {
+				t.Errorf("Run() = %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestParseCommand_UnknownFailOn(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"a.txt": "a = b"})
+
+	err := cli.NewParseCommand().Run([]string{filepath.Join(dir, "a.txt"), "--fail-on", "fatal"})
+	if err == nil || errors.Is(err, cli.ErrProblemsFound) {
+		t.Errorf("expected a usage error for an unknown severity, got %v", err)
+	}
+}
@@ -2,14 +2,27 @@ package cli
 
 import (
 	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/unLomTrois/gock3/pkg/config"
 	"github.com/unLomTrois/gock3/pkg/project"
+	"github.com/unLomTrois/gock3/pkg/report"
 )
 
 type ProjectCommand struct {
-	fs             *flag.FlagSet
-	game_dir       string
-	mod_descriptor string
+	fs              *flag.FlagSet
+	game_dir        string
+	mod_descriptors stringList
+	dlc_load        string
+	config_path     string
+	diagnostics     *diagnosticsOutput
+	fixes           *fixOptions
+	baseline        *baselineOptions
+	threshold       *thresholdOptions
 }
 
 func NewProjectCommand() *ProjectCommand {
@@ -24,13 +37,35 @@ func NewProjectCommand() *ProjectCommand {
 		`gock3 project --game "steamapps/common/Crusader Kings III/game"`,
 	)
 
-	command.fs.StringVar(
-		&command.mod_descriptor,
+	command.fs.Var(
+		&command.mod_descriptors,
 		"mod",
+		`Add a mod to the playset, repeat it for several mods, which load after their dependencies and in the given order otherwise
+gock3 project --mod "Documents/Paradox Interactive/Crusader Kings III/mod/<modname>.mod"`,
+	)
+
+	command.fs.StringVar(
+		&command.dlc_load,
+		"dlc-load",
+		"",
+		`Add the mods enabled in the launcher to the playset, before the ones of --mod
+gock3 project --dlc-load "Documents/Paradox Interactive/Crusader Kings III/dlc_load.json"`,
+	)
+
+	command.fs.StringVar(
+		&command.config_path,
+		"config",
 		"",
-		`gock3 project --mod "Documents/Paradox Interactive/Crusader Kings III/mod/<modname>.mod"`,
+		`Read the settings of the project from a file, flags override them.
+By default `+config.FileName+` is read from the root of the first mod of --mod that has one, from the folder of its descriptor, or from the current directory
+gock3 project --config "mod/<modname>/`+config.FileName+`"`,
 	)
 
+	command.diagnostics = newDiagnosticsOutput(command.fs, "gock3 project --game <game> --mod <mod>")
+	command.fixes = newFixOptions(command.fs, "gock3 project --game <game> --mod <mod>")
+	command.baseline = newBaselineOptions(command.fs, "gock3 project --game <game> --mod <mod>")
+	command.threshold = newThresholdOptions(command.fs, "gock3 project --game <game> --mod <mod>")
+
 	return command
 }
 
@@ -50,13 +85,170 @@ func (c *ProjectCommand) Run(args []string) error {
 		return err
 	}
 
-	project, err := project.NewProject(c.game_dir, c.mod_descriptor)
+	settings, configDiagnostics, err := c.loadConfig()
 	if err != nil {
 		return err
 	}
 
-	project.Load()
+	if err := c.diagnostics.validate(); err != nil {
+		return err
+	}
+
+	if err := c.fixes.validate(); err != nil {
+		return err
+	}
+
+	if err := c.baseline.validate(); err != nil {
+		return err
+	}
+
+	if err := c.threshold.validate(); err != nil {
+		return err
+	}
+
+	descriptors, err := c.descriptors()
+	if err != nil {
+		return err
+	}
+
+	loaded, diagnostics, err := c.analyze(descriptors, settings, configDiagnostics)
+	if err != nil {
+		return err
+	}
+
+	diagnostics, err = c.fixes.apply(diagnostics, func() ([]*report.DiagnosticItem, error) {
+		fixed, diagnostics, err := c.analyze(descriptors, settings, configDiagnostics)
+		if err != nil {
+			return nil, err
+		}
+		loaded = fixed
+		return diagnostics, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	diagnostics, err = c.baseline.apply(diagnostics, projectRoots(loaded)...)
+	if err != nil {
+		return err
+	}
+
+	if err := c.diagnostics.write(diagnostics, sarifRoots(loaded)...); err != nil {
+		return err
+	}
+
+	return c.threshold.check(diagnostics)
+}
+
+// analyze loads the project and returns it with its diagnostics, after the ones of the config file
+// and with the settings of the config file applied
+func (c *ProjectCommand) analyze(descriptors []string, settings *config.Config, configDiagnostics []*report.DiagnosticItem) (*project.Project, []*report.DiagnosticItem, error) {
+	loaded, err := project.NewProject(c.game_dir, descriptors...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := loaded.Load(); err != nil {
+		return nil, nil, err
+	}
+
+	diagnostics := append(slices.Clone(configDiagnostics), loaded.Validate()...)
+	if settings != nil {
+		diagnostics = settings.Apply(diagnostics, projectRoots(loaded)...)
+	}
+	return loaded, diagnostics, nil
+}
+
+// projectRoots returns the directories paths are relative to: the root of their mod, or the game
+func projectRoots(project *project.Project) []string {
+	return append(append([]string{}, project.ModRoots...), project.VanillaDir)
+}
+
+// loadConfig reads the config file, if there is one, and takes the settings that no flag overrides
+func (c *ProjectCommand) loadConfig() (*config.Config, []*report.DiagnosticItem, error) {
+	path := c.config_path
+	if path == "" {
+		found, ok := config.Find(c.configFolders()...)
+		if !ok {
+			return nil, nil, nil
+		}
+		path = found
+	}
+
+	settings, diagnostics, err := config.Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Loaded the settings from %s", path)
+
+	set := make(map[string]bool)
+	c.fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if !set["game"] && settings.GameDir != "" {
+		c.game_dir = settings.GameDir
+	}
+	if !set["mod"] && !set["dlc-load"] && len(settings.ModDescriptors) > 0 {
+		c.mod_descriptors = settings.ModDescriptors
+	}
+	if !set["format"] && settings.Format != "" {
+		c.diagnostics.format = settings.Format
+	}
+
+	return settings, diagnostics, nil
+}
+
+// configFolders returns the folders where the config file is looked up: the roots of the mods of --mod
+// and the folders of their descriptors, and then the current directory, where the config can give the mods itself
+func (c *ProjectCommand) configFolders() []string {
+	var folders []string
+	for _, descriptor := range c.mod_descriptors {
+		if root, err := project.ReadModPath(descriptor); err == nil {
+			folders = append(folders, root)
+		}
+		folders = append(folders, filepath.Dir(descriptor))
+	}
+	return append(folders, ".")
+}
+
+// descriptors returns the descriptors of the mods of the playset, the ones of the launcher first
+func (c *ProjectCommand) descriptors() ([]string, error) {
+	var descriptors []string
+
+	if c.dlc_load != "" {
+		enabled, err := project.ReadDLCLoad(c.dlc_load)
+		if err != nil {
+			return nil, err
+		}
+		descriptors = append(descriptors, enabled...)
+	}
+
+	return append(descriptors, c.mod_descriptors...), nil
+}
+
+// sarifRoots returns the roots of the mods and of the game, with the mods numbered when there are several
+func sarifRoots(project *project.Project) []report.SARIFRoot {
+	var roots []report.SARIFRoot
+	for i, root := range project.ModRoots {
+		id := "MODROOT"
+		if len(project.ModRoots) > 1 {
+			id = fmt.Sprintf("MODROOT%d", i+1)
+		}
+		roots = append(roots, report.SARIFRoot{ID: id, Path: root})
+	}
+	return append(roots, report.SARIFRoot{ID: "GAMEROOT", Path: project.VanillaDir})
+}
+
+// stringList is a flag that can be given several times, such as --mod
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
 
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
 	return nil
 }
 
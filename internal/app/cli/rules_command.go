@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+type RulesCommand struct {
+	flagset *flag.FlagSet
+	out     io.Writer
+}
+
+func NewRulesCommand() *RulesCommand {
+	return &RulesCommand{
+		flagset: flag.NewFlagSet("rules", flag.ExitOnError),
+		out:     os.Stdout,
+	}
+}
+
+func (command *RulesCommand) Name() string {
+	return command.flagset.Name()
+}
+
+func (command *RulesCommand) Description() string {
+	return "List the rules that diagnostics are reported under, or describe the given ones"
+}
+
+// Run lists every rule with its default severity, or describes the rules given in args
+func (command *RulesCommand) Run(args []string) error {
+	if err := command.flagset.Parse(args); err != nil {
+		return err
+	}
+
+	if command.flagset.NArg() == 0 {
+		w := tabwriter.NewWriter(command.out, 0, 0, 2, ' ', 0)
+		for _, rule := range rules.All() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", rule.ID, rule.Severity, rule.Summary)
+		}
+		return w.Flush()
+	}
+
+	for i, id := range command.flagset.Args() {
+		rule, ok := rules.Lookup(rules.ID(id))
+		if !ok {
+			return fmt.Errorf("unknown rule: %s", id)
+		}
+
+		if i > 0 {
+			fmt.Fprintln(command.out)
+		}
+		fmt.Fprintf(command.out, "%s (%s)\n%s\n\n%s\n", rule.ID, rule.Severity, rule.Summary, rule.Description)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/cli"
+)
+
+func TestRulesCommand_Name(t *testing.T) {
+	cmd := cli.NewRulesCommand()
+	if cmd.Name() != "rules" {
+		t.Errorf("RulesCommand.Name() = %v, want %v", cmd.Name(), "rules")
+	}
+}
+
+func TestRulesCommand_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"list", nil, false},
+		{"describe", []string{"parse/unclosed-block", "trait/banned-field"}, false},
+		{"unknown rule", []string{"parse/no-such-rule"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cli.NewRulesCommand().Run(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// ErrProblemsFound is returned by commands that ran to the end but found problems at or above the threshold.
+// The process exits with 1 for it, and with 2 for any other error.
+var ErrProblemsFound = errors.New("problems found")
+
+// failOnLevels are the severities --fail-on accepts
+var failOnLevels = []string{"warning", "error", "critical"}
+
+// thresholdOptions holds the flags that decide whether the diagnostics of a command make it fail
+type thresholdOptions struct {
+	failOn string
+	// maxErrors is the number of diagnostics at or above the threshold that are tolerated, nil if not given
+	maxErrors *int
+	stderr    io.Writer
+}
+
+func newThresholdOptions(flagset *flag.FlagSet, example string) *thresholdOptions {
+	options := &thresholdOptions{stderr: os.Stderr}
+
+	flagset.StringVar(
+		&options.failOn,
+		"fail-on",
+		"error",
+		fmt.Sprintf("Exit with 1 when there are diagnostics of this severity or a higher one: %s\n%s --fail-on warning", strings.Join(failOnLevels, ", "), example),
+	)
+
+	flagset.Func(
+		"max-errors",
+		fmt.Sprintf("Exit with 1 only when there are more than this number of diagnostics at or above --fail-on\n%s --max-errors 10", example),
+		func(value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("expected a number of at least 0")
+			}
+			options.maxErrors = &n
+			return nil
+		},
+	)
+
+	return options
+}
+
+// validate checks the flags before the command does any work
+func (o *thresholdOptions) validate() error {
+	for _, level := range failOnLevels {
+		if o.failOn == level {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q for --fail-on, expected one of %s", o.failOn, strings.Join(failOnLevels, ", "))
+}
+
+// check writes the summary of the diagnostics, and returns ErrProblemsFound when they exceed the threshold.
+// Without --fail-on and --max-errors any error makes the command fail.
+func (o *thresholdOptions) check(items []*report.DiagnosticItem) error {
+	summary := report.Summarize(items)
+	if err := summary.Write(o.stderr); err != nil {
+		return err
+	}
+
+	threshold, _ := severity.Parse(o.failOn)
+	allowed := 0
+	if o.maxErrors != nil {
+		allowed = *o.maxErrors
+	}
+
+	if count := summary.AtLeast(threshold); count > allowed {
+		return fmt.Errorf("%w: %d at or above %s, %d allowed", ErrProblemsFound, count, strings.ToLower(threshold.String()), allowed)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+// Package diff computes line-based differences between two texts and prints them as unified diffs.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+type OpKind uint8
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// Op is a single line of an edit script.
+// OldLine and NewLine are 0-based indices into the old and new lines.
+type Op struct {
+	Kind    OpKind
+	OldLine int
+	NewLine int
+	Text    string
+}
+
+// SplitLines splits text into lines that keep their line endings
+func SplitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns an edit script that turns the old lines into the new ones
+func Lines(old, new []string) []Op {
+	d := &differ{
+		a:        old,
+		b:        new,
+		deleted:  make([]bool, len(old)),
+		inserted: make([]bool, len(new)),
+	}
+	d.compare(0, len(old), 0, len(new))
+
+	var ops []Op
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && d.deleted[i]:
+			ops = append(ops, Op{Kind: Delete, OldLine: i, NewLine: j, Text: old[i]})
+			i++
+		case j < len(new) && d.inserted[j]:
+			ops = append(ops, Op{Kind: Insert, OldLine: i, NewLine: j, Text: new[j]})
+			j++
+		default:
+			ops = append(ops, Op{Kind: Equal, OldLine: i, NewLine: j, Text: old[i]})
+			i++
+			j++
+		}
+	}
+
+	return ops
+}
+
+// Unified returns the unified diff between two texts, or an empty string if they are equal
+func Unified(oldName string, newName string, old string, new string) string {
+	if old == new {
+		return ""
+	}
+
+	ops := Lines(SplitLines(old), SplitLines(new))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].Kind == Equal {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are close enough to share context
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].Kind != Equal {
+				end = i + 1
+			} else if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		from := max(0, start-contextLines)
+		to := min(len(ops), end+contextLines)
+		writeHunk(&out, ops[from:to])
+		start = to
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []Op) {
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.Kind != Insert {
+			oldCount++
+		}
+		if op.Kind != Delete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(ops[0].OldLine, oldCount), hunkRange(ops[0].NewLine, newCount))
+
+	for _, op := range ops {
+		prefix := " "
+		switch op.Kind {
+		case Delete:
+			prefix = "-"
+		case Insert:
+			prefix = "+"
+		}
+
+		out.WriteString(prefix)
+		out.WriteString(op.Text)
+		if !strings.HasSuffix(op.Text, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a 0-based start line and a line count the way diff and patch expect
+func hunkRange(start int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// differ implements the linear space variant of Myers' algorithm,
+// marking the lines that are deleted from a and inserted into b.
+type differ struct {
+	a, b              []string
+	deleted, inserted []bool
+}
+
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// Skip the common prefix and suffix
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	if aLo == aHi || bLo == bHi {
+		d.markChanged(aLo, aHi, bLo, bHi)
+		return
+	}
+
+	x0, y0, x1, y1 := d.middleSnake(aLo, aHi, bLo, bHi)
+
+	// The middle snake always splits the problem, but never loop if it doesn't
+	if (x0 == aLo && y0 == bLo && x1 == aLo && y1 == bLo) || (x0 == aHi && y0 == bHi) {
+		d.markChanged(aLo, aHi, bLo, bHi)
+		return
+	}
+
+	d.compare(aLo, x0, bLo, y0)
+	d.compare(x1, aHi, y1, bHi)
+}
+
+func (d *differ) markChanged(aLo, aHi, bLo, bHi int) {
+	for i := aLo; i < aHi; i++ {
+		d.deleted[i] = true
+	}
+	for j := bLo; j < bHi; j++ {
+		d.inserted[j] = true
+	}
+}
+
+// middleSnake finds the middle snake of an optimal edit path between a[aLo:aHi] and b[bLo:bHi],
+// returning the absolute coordinates of its start and end.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+
+	// Furthest reaching x on each diagonal k, going forward from the start and backward from the end
+	offset := limit + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for step := 0; step <= limit; step++ {
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			if reverse := delta - k; odd && reverse >= -(step-1) && reverse <= step-1 && x+backward[offset+reverse] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			if forwardK := delta - k; !odd && forwardK >= -step && forwardK <= step && x+forward[offset+forwardK] >= n {
+				return aHi - x, bHi - y, aHi - startX, bHi - startY
+			}
+		}
+	}
+
+	// Unreachable for valid input
+	return aLo, bLo, aLo, bLo
+}
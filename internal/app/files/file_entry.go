@@ -1,8 +1,9 @@
 package files
 
 import (
-	"os"
+	"io/fs"
 	"path/filepath"
+	"strings"
 )
 
 type FileKind uint8
@@ -12,27 +13,66 @@ const (
 	Mod
 )
 
+func (kind FileKind) String() string {
+	switch kind {
+	case Vanilla:
+		return "vanilla"
+	case Mod:
+		return "mod"
+	default:
+		return "unknown"
+	}
+}
+
+// Source is the game or the mod that a file comes from
+type Source struct {
+	// Name is the name of the mod, empty for the game and for files given on their own
+	Name string
+	// Order is the position of the mod in the load order starting at 1, and 0 for the game.
+	// Files of a later mod override the ones of earlier mods and of the game.
+	Order int
+}
+
 type FileEntry struct {
-	// The full filesystem path of this entry
+	// The full filesystem path of this entry, as shown in diagnostics
 	fullpath string
+	// The file system the file is read from, and its path there
+	fsys fs.FS
+	name string
+	// The path relative to the game or mod root, with forward slashes, empty if the entry wasn't scanned
+	path string
+	// The vanilla file that this mod file overrides, if any
+	replaces *FileEntry
+	// The table the path is stored in, that of the project or one of its own if nil
+	paths *PathTable
 	// Index into the PathTable (optional, using *PathTableIndex to allow nil)
 	idx *PathTableIndex
 	// Whether it's a vanilla or mod file
 	kind FileKind
+	// The game or the mod the file comes from
+	source Source
 }
 
-// NewFileEntry is the constructor for FileEntry.
-// Ensures the path is valid and not empty.
-func NewFileEntry(fullpath string, kind FileKind) *FileEntry {
-	if _, err := os.Stat(fullpath); os.IsNotExist(err) {
-		panic("Invalid path: path does not exist")
+// NewFileEntryFS creates the entry of the file at name in fsys, shown as fullpath in diagnostics.
+// Its path is stored in paths, or in a table of its own if paths is nil. A missing file is an error.
+func NewFileEntryFS(paths *PathTable, fsys fs.FS, name string, fullpath string, kind FileKind) (*FileEntry, error) {
+	if _, err := fs.Stat(fsys, name); err != nil {
+		return nil, err
 	}
 
-	return &FileEntry{
+	entry := &FileEntry{
 		fullpath: fullpath,
+		fsys:     fsys,
+		name:     name,
+		paths:    paths,
 		kind:     kind,
 		idx:      nil,
 	}
+	// A mod file given on its own loads after the game
+	if kind == Mod {
+		entry.source.Order = 1
+	}
+	return entry, nil
 }
 
 // Kind returns the file kind (vanilla or mod).
@@ -45,16 +85,49 @@ func (fe *FileEntry) FullPath() string {
 	return fe.fullpath
 }
 
+// ReadFile returns the content of the file, byte order mark included
+func (fe *FileEntry) ReadFile() ([]byte, error) {
+	return fs.ReadFile(fe.fsys, fe.name)
+}
+
+// Source returns the game or the mod the file comes from
+func (fe *FileEntry) Source() Source {
+	return fe.source
+}
+
+// Path returns the path relative to the game or mod root with forward slashes, such as common/traits/00_traits.txt.
+// It is empty for entries that were not found by Scan.
+func (fe *FileEntry) Path() string {
+	return fe.path
+}
+
+// InFolder reports whether the file is in the folder or one of its subfolders.
+// The folder is relative to the game or mod root with forward slashes, such as common/traits.
+func (fe *FileEntry) InFolder(folder string) bool {
+	return fe.path != "" && strings.HasPrefix(fe.path, strings.Trim(folder, "/")+"/")
+}
+
+// Replaces returns the file of the game or of an earlier mod that this mod file overrides, or nil
+func (fe *FileEntry) Replaces() *FileEntry {
+	return fe.replaces
+}
+
 // FileName returns the file name, ensuring it's not empty.
 func (fe *FileEntry) FileName() string {
 	return filepath.Base(fe.fullpath)
 }
 
+// StoreInPathTable stores the path of the entry in its table, see OpenFileEntry and ScanPlaysetFS,
+// or in a table of its own for an entry that doesn't belong to a project
 func (fe *FileEntry) StoreInPathTable() *PathTableIndex {
 	if fe.idx != nil {
 		return fe.idx
 	}
-	fe.idx = PATHTABLE.Store(fe.fullpath)
+	if fe.paths == nil {
+		fe.paths = NewPathTable()
+	}
+	idx := fe.paths.StoreEntry(fe)
+	fe.idx = &idx
 	return fe.idx
 }
 
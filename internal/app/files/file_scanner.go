@@ -3,72 +3,134 @@ package files
 import (
 	"io/fs"
 	"log"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // Scan scans two directories (the game folder and the mod folder) to find .txt files,
-// including those in subdirectories. If there is a filename collision, the file in the mod folder
-// takes precedence. A list of paths (replacePaths) can be provided so that any matching
-// subdirectory under the game folder will be skipped, effectively giving priority to the mod folder
-// for that subdirectory.
+// including those in subdirectories. It is ScanPlayset with a single mod.
 func Scan(gameFolder string, modFolder string, replacePaths []string) ([]*FileEntry, error) {
-	// Normalize replacePaths to clean directory paths
-	normalizedReplacePaths := make([]string, 0, len(replacePaths))
-	for _, path := range replacePaths {
-		normalizedReplacePaths = append(normalizedReplacePaths, filepath.Clean(path))
-	}
+	return ScanPlayset(gameFolder, NewModLoader("", modFolder, replacePaths))
+}
+
+// ScanPlayset scans the game folder and the folders of the mods, in load order, to find .txt files,
+// including those in subdirectories. Files are identified by their path relative to their folder,
+// as the game does: a mod file overrides the file of the game or of an earlier mod with the same
+// relative path, and records it as the file it replaces. The replace_path entries of a mod leave out
+// the files loaded before it directly in their folder, see ReplacedBy. The entries are sorted by relative path.
+// The folders are read from the disk, and the paths are stored in a new table, see ScanPlaysetFS.
+func ScanPlayset(gameFolder string, mods ...*ModLoader) ([]*FileEntry, error) {
+	return ScanPlaysetFS(NewPathTable(), diskFS{os.DirFS(gameFolder)}, gameFolder, mods...)
+}
 
-	// A map to hold files uniquely by their filename
+// ScanPlaysetFS is ScanPlayset with the game read from game, and the mods from their FS, or from the disk if they have none.
+// Entries are shown in diagnostics under the game folder and the roots of the mods, and their paths are stored in paths.
+func ScanPlaysetFS(paths *PathTable, game fs.FS, gameFolder string, mods ...*ModLoader) ([]*FileEntry, error) {
+	// A map to hold files uniquely by their path relative to the folder
 	fileMap := make(map[string]*FileEntry)
 
-	// Helper function to handle skipping directories and adding files
-	scanFunc := func(root string, kind FileKind) fs.WalkDirFunc {
-		return func(subpath string, d fs.DirEntry, err error) error {
+	// Helper function to handle adding files, whose paths in fsys are relative to the folder
+	scanFunc := func(fsys fs.FS, root string, kind FileKind, source Source) fs.WalkDirFunc {
+		return func(relative string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
 
-			// Skip entire directories if they match any replacePath
-			if d.IsDir() && kind == Vanilla {
-				for _, replacePath := range normalizedReplacePaths {
-					if strings.Contains(subpath, replacePath) {
-						return filepath.SkipDir
-					}
-				}
-				return nil
-			}
-
 			// Only consider .txt files
-			if !strings.HasSuffix(subpath, ".txt") {
+			if d.IsDir() || !strings.HasSuffix(relative, ".txt") {
 				return nil
 			}
 
-			filename := filepath.Base(subpath)
-			fileEntry := NewFileEntry(subpath, kind)
-			fileMap[filename] = fileEntry
+			fileEntry := &FileEntry{
+				fullpath: filepath.Join(root, filepath.FromSlash(relative)),
+				fsys:     fsys,
+				name:     relative,
+				paths:    paths,
+				path:     relative,
+				kind:     kind,
+				source:   source,
+				replaces: fileMap[relative],
+			}
+			fileMap[relative] = fileEntry
 
 			return nil
 		}
 	}
 
 	log.Printf("Scanning game folder: %s\n", gameFolder)
-	err := filepath.WalkDir(gameFolder, scanFunc(gameFolder, Vanilla))
+	err := fs.WalkDir(game, ".", scanFunc(game, gameFolder, Vanilla, Source{}))
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("Scanning mod folder: %s\n", modFolder)
-	if err := filepath.WalkDir(modFolder, scanFunc(modFolder, Mod)); err != nil {
-		return nil, err
+	for i, mod := range mods {
+		// The replace paths of a mod apply to what is loaded before it
+		replaceFolders := NormalizeReplacePaths(mod.ReplacePaths)
+		for relative := range fileMap {
+			if ReplacedBy(relative, replaceFolders) {
+				delete(fileMap, relative)
+			}
+		}
+
+		modFS := mod.FS
+		if modFS == nil {
+			modFS = diskFS{os.DirFS(mod.Root)}
+		}
+
+		log.Printf("Scanning mod folder: %s\n", mod.Root)
+		source := Source{Name: mod.Name, Order: i + 1}
+		if err := fs.WalkDir(modFS, ".", scanFunc(modFS, mod.Root, Mod, source)); err != nil {
+			return nil, err
+		}
 	}
 
-	// Collect file entries from the map
-	var fileEntries []*FileEntry
+	// Collect file entries from the map, in a stable order
+	fileEntries := make([]*FileEntry, 0, len(fileMap))
 	for _, entry := range fileMap {
 		fileEntries = append(fileEntries, entry)
 	}
+	sort.Slice(fileEntries, func(i, j int) bool {
+		return fileEntries[i].path < fileEntries[j].path
+	})
 
-	log.Printf("Found %d files\n", len(fileEntries))
+	overrides := 0
+	for _, entry := range fileEntries {
+		if entry.replaces != nil {
+			overrides++
+		}
+	}
+
+	log.Printf("Found %d files, %d of them override files loaded before\n", len(fileEntries), overrides)
 	return fileEntries, nil
 }
+
+// NormalizeReplacePaths cleans the replace_path entries of a mod descriptor into folders
+// relative to the game root with forward slashes, leaving out the empty ones
+func NormalizeReplacePaths(replacePaths []string) []string {
+	folders := make([]string, 0, len(replacePaths))
+	for _, replacePath := range replacePaths {
+		folder := path.Clean(strings.ReplaceAll(replacePath, "\\", "/"))
+		folder = strings.Trim(folder, "/")
+		if folder == "" || folder == "." {
+			continue
+		}
+		folders = append(folders, folder)
+	}
+	return folders
+}
+
+// ReplacedBy reports whether one of the normalized replace paths leaves out the vanilla file,
+// given by its path relative to the game root with forward slashes.
+// As in the game, a replace path applies to the files directly in its folder, and not to its subfolders.
+func ReplacedBy(relative string, replaceFolders []string) bool {
+	dir := path.Dir(relative)
+	for _, folder := range replaceFolders {
+		if dir == folder {
+			return true
+		}
+	}
+	return false
+}
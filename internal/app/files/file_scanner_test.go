@@ -0,0 +1,218 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree creates empty files at the slash-separated paths under root
+func writeTree(t *testing.T, root string, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		fullpath := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullpath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullpath, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	type entry struct {
+		Path     string
+		Kind     FileKind
+		Replaces string
+	}
+
+	tests := []struct {
+		name    string
+		vanilla []string
+		mod     []string
+		replace []string
+		want    []entry
+	}{
+		{
+			name:    "same name in other folders",
+			vanilla: []string{"common/traits/00_traits.txt", "history/characters/00_traits.txt"},
+			mod:     []string{"events/00_traits.txt"},
+			want: []entry{
+				{"common/traits/00_traits.txt", Vanilla, ""},
+				{"events/00_traits.txt", Mod, ""},
+				{"history/characters/00_traits.txt", Vanilla, ""},
+			},
+		},
+		{
+			name:    "override by relative path",
+			vanilla: []string{"common/traits/00_traits.txt", "common/traits/01_traits.txt", "history/characters/00_traits.txt"},
+			mod:     []string{"common/traits/00_traits.txt", "common/traits/zz_mod.txt"},
+			want: []entry{
+				{"common/traits/00_traits.txt", Mod, "common/traits/00_traits.txt"},
+				{"common/traits/01_traits.txt", Vanilla, ""},
+				{"common/traits/zz_mod.txt", Mod, ""},
+				{"history/characters/00_traits.txt", Vanilla, ""},
+			},
+		},
+		{
+			name:    "replace path",
+			vanilla: []string{"history/characters/a.txt", "history/characters/sub/b.txt", "history/characters_extra/c.txt", "history/d.txt"},
+			mod:     []string{"history/characters/e.txt"},
+			replace: []string{"history/characters"},
+			want: []entry{
+				{"history/characters/e.txt", Mod, ""},
+				{"history/characters/sub/b.txt", Vanilla, ""},
+				{"history/characters_extra/c.txt", Vanilla, ""},
+				{"history/d.txt", Vanilla, ""},
+			},
+		},
+		{
+			name:    "only text files",
+			vanilla: []string{"common/traits/00_traits.txt", "gfx/portrait.dds"},
+			mod:     []string{"descriptor.mod", "thumbnail.png"},
+			want: []entry{
+				{"common/traits/00_traits.txt", Vanilla, ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, mod := t.TempDir(), t.TempDir()
+			writeTree(t, game, tt.vanilla...)
+			writeTree(t, mod, tt.mod...)
+
+			entries, err := Scan(game, mod, tt.replace)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []entry
+			for _, e := range entries {
+				root := game
+				if e.Kind() == Mod {
+					root = mod
+				}
+				if want := filepath.Join(root, filepath.FromSlash(e.Path())); e.FullPath() != want {
+					t.Errorf("FullPath() = %q, want %q", e.FullPath(), want)
+				}
+
+				replaces := ""
+				if e.Replaces() != nil {
+					if e.Replaces().Kind() != Vanilla || e.Replaces().FullPath() != filepath.Join(game, filepath.FromSlash(e.Replaces().Path())) {
+						t.Errorf("%s replaces %q, which is not a file of the game", e.Path(), e.Replaces().FullPath())
+					}
+					replaces = e.Replaces().Path()
+				}
+				got = append(got, entry{e.Path(), e.Kind(), replaces})
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplacedBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace []string
+		path    string
+		want    bool
+	}{
+		{"file in the folder", []string{"history/characters"}, "history/characters/a.txt", true},
+		{"file in a subfolder", []string{"history/characters"}, "history/characters/sub/a.txt", false},
+		{"folder with the same prefix", []string{"history/characters"}, "history/characters_extra/a.txt", false},
+		{"parent folder", []string{"history/characters"}, "history/a.txt", false},
+		{"folder of the same name elsewhere", []string{"history/characters"}, "events/history/characters/a.txt", false},
+		{"trailing slash", []string{"history/characters/"}, "history/characters/a.txt", true},
+		{"leading slash", []string{"/history/characters"}, "history/characters/a.txt", true},
+		{"backslashes", []string{`history\characters`}, "history/characters/a.txt", true},
+		{"dots", []string{"history/./characters/../characters"}, "history/characters/a.txt", true},
+		{"several paths", []string{"common/traits", "history/characters"}, "common/traits/a.txt", true},
+		{"empty path", []string{""}, "a.txt", false},
+		{"no paths", nil, "history/characters/a.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReplacedBy(tt.path, NormalizeReplacePaths(tt.replace)); got != tt.want {
+				t.Errorf("ReplacedBy(%q, %q) = %v, want %v", tt.path, tt.replace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileEntry_InFolder(t *testing.T) {
+	tests := []struct {
+		path   string
+		folder string
+		want   bool
+	}{
+		{"common/traits/a.txt", "common/traits", true},
+		{"common/traits/sub/a.txt", "common/traits", true},
+		{"common/traits/a.txt", "common", true},
+		{"common/traits/a.txt", "common/traits/", true},
+		{"common/traits_extra/a.txt", "common/traits", false},
+		{"events/common/traits/a.txt", "common/traits", false},
+		{"common/a.txt", "common/traits", false},
+		{"", "common", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+" in "+tt.folder, func(t *testing.T) {
+			entry := &FileEntry{path: tt.path}
+			if got := entry.InFolder(tt.folder); got != tt.want {
+				t.Errorf("InFolder(%q) of %q = %v, want %v", tt.folder, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanPlayset(t *testing.T) {
+	game, first, second := t.TempDir(), t.TempDir(), t.TempDir()
+	writeTree(t, game, "common/traits/00_traits.txt", "common/traits/01_traits.txt", "history/characters/a.txt")
+	writeTree(t, first, "common/traits/00_traits.txt", "common/traits/10_first.txt", "history/characters/b.txt")
+	writeTree(t, second, "common/traits/00_traits.txt", "common/traits/20_second.txt")
+
+	entries, err := ScanPlayset(game,
+		NewModLoader("First", first, nil),
+		// The second mod replaces the characters of the game and of the first mod
+		NewModLoader("Second", second, []string{"history/characters"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		Path     string
+		Source   Source
+		Replaces Source
+	}
+	want := []entry{
+		{"common/traits/00_traits.txt", Source{"Second", 2}, Source{"First", 1}},
+		{"common/traits/01_traits.txt", Source{}, Source{}},
+		{"common/traits/10_first.txt", Source{"First", 1}, Source{}},
+		{"common/traits/20_second.txt", Source{"Second", 2}, Source{}},
+	}
+
+	var got []entry
+	for _, e := range entries {
+		var replaces Source
+		if e.Replaces() != nil {
+			replaces = e.Replaces().Source()
+			if e.Replaces().Replaces() == nil || e.Replaces().Replaces().Kind() != Vanilla {
+				t.Errorf("%s of %s doesn't replace the file of the game", e.Path(), replaces.Name)
+			}
+		}
+		got = append(got, entry{e.Path(), e.Source(), replaces})
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanPlayset() = %+v, want %+v", got, want)
+	}
+}
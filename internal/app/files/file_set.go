@@ -1,27 +1,36 @@
 package files
 
+import "io/fs"
+
 type FileSet struct {
 	// path to ck3/game
 	VanillaRoot string
-	ModLoader   *ModLoader
-	Files       []*FileEntry
+	// Mods are the mods of the playset, in load order
+	Mods  []*ModLoader
+	Files []*FileEntry
 }
 
 type ModLoader struct {
+	// Name of the mod, as in its descriptor
+	Name string
 	// path to ck3/mod
 	Root         string
 	ReplacePaths []string
+	// FS is the file system of the folder, such as a zip archive of the mod, see OpenFolder.
+	// The folder is read from the disk if it is nil.
+	FS fs.FS
 }
 
-func NewFileSet(vanillaRoot string, modLoader *ModLoader) *FileSet {
+func NewFileSet(vanillaRoot string, mods ...*ModLoader) *FileSet {
 	return &FileSet{
 		VanillaRoot: vanillaRoot,
-		ModLoader:   modLoader,
+		Mods:        mods,
 	}
 }
 
-func NewModLoader(modRoot string, replacePaths []string) *ModLoader {
+func NewModLoader(name string, modRoot string, replacePaths []string) *ModLoader {
 	return &ModLoader{
+		Name:         name,
 		Root:         modRoot,
 		ReplacePaths: replacePaths,
 	}
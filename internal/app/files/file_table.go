@@ -2,81 +2,163 @@ package files
 
 import (
 	"errors"
+	"io/fs"
+	"os"
 	"sync"
 )
 
 // Error to be returned if the index is out of bounds
 var ErrIndexOutOfBounds = errors.New("index out of bounds")
 
+// Error to be returned if the index was stored by another table
+var ErrOtherPathTable = errors.New("index of another path table")
+
+// PathTableIndex is a path stored in a PathTable. It resolves against the table that stored it,
+// so the indices of different tables never mix up, and the zero index points at no path.
 type PathTableIndex struct {
+	table *PathTable
 	index uint32
 }
 
-type PathTable interface {
-	Store(fullpath string) PathTableIndex
+// Fullpath returns the path stored at the index
+func (idx PathTableIndex) Fullpath() (string, error) {
+	return idx.table.LookupFullpath(idx)
+}
+
+// Source returns the game or mod the file at the index comes from
+func (idx PathTableIndex) Source() (Source, error) {
+	return idx.table.LookupSource(idx)
+}
+
+// ReadFile returns the content of the file at the index, see PathTable.ReadFile
+func (idx PathTableIndex) ReadFile() ([]byte, error) {
+	return idx.table.ReadFile(idx)
+}
+
+// OnDisk reports whether the file at the index is read from the disk at its full path, see PathTable.OnDisk
+func (idx PathTableIndex) OnDisk() bool {
+	return idx.table.OnDisk(idx)
 }
 
 type PathTableStore struct {
 	fullpath string
+	source   Source
+	// The file system the file is read from and its path there, nil for a path on the disk
+	fsys fs.FS
+	name string
 }
 
-// Singleton of PathTable
-type pathTable struct {
-	paths []PathTableStore
-	mu    sync.RWMutex
+// PathTable interns the paths of files, so that locations hold a small index instead of a path.
+// A project owns its table, and the paths go away with the project; files that don't belong
+// to a project get a table of their own. It is safe for concurrent use.
+type PathTable struct {
+	mu     sync.RWMutex
+	paths  []PathTableStore
+	byPath map[string]uint32
 }
 
-type PathTableStatic struct{}
-
-var (
-	pathTableInstance *pathTable
-	once              sync.Once
-	PATHTABLE         PathTableStatic
-)
+func NewPathTable() *PathTable {
+	return &PathTable{
+		paths:  make([]PathTableStore, 0),
+		byPath: make(map[string]uint32),
+	}
+}
 
-func GetPathTableInstance() *pathTable {
-	once.Do(func() {
-		pathTableInstance = &pathTable{
-			paths: make([]PathTableStore, 0),
-			mu:    sync.RWMutex{},
-		}
-	})
-	return pathTableInstance
+// Store stores a path of the disk and returns its index. Storing a path again returns the same index.
+func (pt *PathTable) Store(fullpath string) PathTableIndex {
+	return pt.store(PathTableStore{fullpath: fullpath})
 }
 
-// Store is usually called from PATHTABLE, which is a "static namespace" of the package.
-// Hence public Store method should call GetPathTableInstance to get an actual singleton, and call its private store method
-func (PathTableStatic) Store(fullpath string) *PathTableIndex {
-	return GetPathTableInstance().store(fullpath)
+// StoreEntry is like Store, but also keeps the game or mod the file comes from, and where to read it.
+// The first entry stored for a path decides both.
+func (pt *PathTable) StoreEntry(entry *FileEntry) PathTableIndex {
+	return pt.store(PathTableStore{
+		fullpath: entry.fullpath,
+		source:   entry.source,
+		fsys:     entry.fsys,
+		name:     entry.name,
+	})
 }
 
-func (pt *pathTable) store(fullpath string) *PathTableIndex {
+func (pt *PathTable) store(file PathTableStore) PathTableIndex {
 	pt.mu.Lock()
 	defer pt.mu.Unlock()
-	idx := &PathTableIndex{index: uint32(len(pt.paths))}
-	pt.paths = append(pt.paths, PathTableStore{fullpath: fullpath})
-	return idx
+
+	if index, ok := pt.byPath[file.fullpath]; ok {
+		return PathTableIndex{table: pt, index: index}
+	}
+
+	index := uint32(len(pt.paths))
+	pt.paths = append(pt.paths, file)
+	pt.byPath[file.fullpath] = index
+	return PathTableIndex{table: pt, index: index}
 }
 
-// Public LookupFullpath method that calls the private method after getting the singleton instance.
-func (PathTableStatic) LookupFullpath(index PathTableIndex) (string, error) {
-	return GetPathTableInstance().lookupFullpath(index)
+// Len returns the number of paths in the table
+func (pt *PathTable) Len() int {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+
+	return len(pt.paths)
 }
 
-// Private lookupFullpath method with RLock and RUnlock for thread-safe read access.
-func (pt *pathTable) lookupFullpath(index PathTableIndex) (string, error) {
+// lookup returns what is stored at the index, with RLock and RUnlock for thread-safe read access
+func (pt *PathTable) lookup(index PathTableIndex) (PathTableStore, error) {
+	if pt == nil {
+		return PathTableStore{}, ErrIndexOutOfBounds
+	}
+	if index.table != pt {
+		return PathTableStore{}, ErrOtherPathTable
+	}
+
 	pt.mu.RLock()
 	defer pt.mu.RUnlock()
 
 	if index.index >= uint32(len(pt.paths)) {
-		return "", ErrIndexOutOfBounds
+		return PathTableStore{}, ErrIndexOutOfBounds
 	}
 
-	return pt.paths[index.index].fullpath, nil
+	return pt.paths[index.index], nil
 }
 
-// ResetPathTable is a helper function to reset the singleton for testing purposes.
-func resetPathTable() {
-	pathTableInstance = GetPathTableInstance()
-	pathTableInstance.paths = make([]PathTableStore, 0)
+// LookupFullpath returns the path stored at the index
+func (pt *PathTable) LookupFullpath(index PathTableIndex) (string, error) {
+	file, err := pt.lookup(index)
+	if err != nil {
+		return "", err
+	}
+	return file.fullpath, nil
+}
+
+// LookupSource returns the game or mod the file at the index comes from
+func (pt *PathTable) LookupSource(index PathTableIndex) (Source, error) {
+	file, err := pt.lookup(index)
+	if err != nil {
+		return Source{}, err
+	}
+	return file.source, nil
+}
+
+// ReadFile returns the content of the file at the index, byte order mark included.
+// Files stored by their path alone are read from the disk.
+func (pt *PathTable) ReadFile(index PathTableIndex) ([]byte, error) {
+	file, err := pt.lookup(index)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.fsys == nil {
+		return os.ReadFile(file.fullpath)
+	}
+	return fs.ReadFile(file.fsys, file.name)
+}
+
+// OnDisk reports whether the file at the index is read from the disk at its full path, so that it can be written there.
+// Files of an archive, of memory and unsaved buffers are not.
+func (pt *PathTable) OnDisk(index PathTableIndex) bool {
+	file, err := pt.lookup(index)
+	if err != nil {
+		return false
+	}
+	return OnDisk(file.fsys, file.name)
 }
@@ -1,120 +1,72 @@
 package files
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
-	"reflect"
 	"sync"
 	"testing"
 )
 
-func TestGetPathTableInstance(t *testing.T) {
-	resetPathTable()
+func TestPathTable_Store(t *testing.T) {
+	pt := NewPathTable()
 
 	tests := []struct {
-		name string
-		want *pathTable
+		name     string
+		fullpath string
+		want     uint32
 	}{
 		{
-			name: "Check Singleton Instance",
-			want: GetPathTableInstance(), // Expect the same instance to be returned
+			name:     "Store new path",
+			fullpath: filepath.Join("full", "path"),
+			want:     0, // Expected index after first insertion
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := GetPathTableInstance()
-			if got != tt.want { // Compare instances (pointer equality check)
-				t.Errorf("GetPathTableInstance() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_pathTable_Store(t *testing.T) {
-	// Reset the singleton before the test starts
-	resetPathTable()
-
-	type args struct {
-		fullpath string
-	}
-	tests := []struct {
-		name string
-		args args
-		want PathTableIndex
-	}{
 		{
-			name: "Store new path",
-			args: args{
-				fullpath: filepath.Join("full", "path"),
-			},
-			want: PathTableIndex{index: 0}, // Expected index after first insertion
+			name:     "Store second path",
+			fullpath: filepath.Join("full2", "path"),
+			want:     1, // Expected index after second insertion
 		},
 		{
-			name: "Store second path",
-			args: args{
-				fullpath: filepath.Join("full2", "path"),
-			},
-			want: PathTableIndex{index: 1}, // Expected index after second insertion
+			name:     "Store the first path again",
+			fullpath: filepath.Join("full", "path"),
+			want:     0,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Use PATHTABLE to store paths, which will call GetPathTableInstance() internally
-			got := PATHTABLE.Store(tt.args.fullpath)
-			if got.index != tt.want.index {
-				t.Errorf("PATHTABLE.Store() = %v, want %v", got, tt.want)
+			got := pt.Store(tt.fullpath)
+			if got.index != tt.want || got.table != pt {
+				t.Errorf("PathTable.Store() = %v, want index %v of the table", got, tt.want)
 			}
 		})
 	}
+
+	if pt.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", pt.Len())
+	}
 }
 
-func Test_pathTable_store(t *testing.T) {
-	resetPathTable()
+func TestPathTable_StoreEntry(t *testing.T) {
+	pt := NewPathTable()
+	first := &FileEntry{fullpath: filepath.Join("mod", "a.txt"), source: Source{Name: "Mod", Order: 1}}
+	second := &FileEntry{fullpath: filepath.Join("mod", "a.txt")}
 
-	type args struct {
-		fullpath string
+	if a, b := pt.StoreEntry(first), pt.StoreEntry(second); a != b {
+		t.Errorf("StoreEntry() of the same path = %v and %v, want the same index", a, b)
 	}
-	tests := []struct {
-		name string
-		pt   *pathTable
-		args args
-		want *PathTableIndex
-	}{
-		{
-			name: "Store new path",
-			pt:   GetPathTableInstance(),
-			args: args{
-				fullpath: filepath.Join("full", "path"),
-			},
-			want: &PathTableIndex{index: 0}, // Expected index after first insertion
-		},
-		{
-			name: "Store second path",
-			pt:   GetPathTableInstance(),
-			args: args{
-				fullpath: filepath.Join("full2", "path"),
-			},
-			want: &PathTableIndex{index: 1}, // Expected index after second insertion
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := tt.pt.store(tt.args.fullpath)
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("pathTable.store() = %v, want %v", got, tt.want)
-			}
-		})
+	if source, _ := pt.LookupSource(pt.Store(first.fullpath)); source != first.source {
+		t.Errorf("LookupSource() = %+v, want the source of the first entry", source)
 	}
 }
 
-func Test_pathTable_Store_Concurrent(t *testing.T) {
-	resetPathTable()
+func TestPathTable_Store_Concurrent(t *testing.T) {
+	pt := NewPathTable()
 
 	var wg sync.WaitGroup
 	numGoroutines := 10
 
-	// Expected number of paths stored should match the number of goroutines
-	expectedPaths := numGoroutines
+	// Every path is stored by two goroutines, and stored once
+	expectedPaths := numGoroutines / 2
 
 	// Run Store method concurrently in multiple goroutines
 	for i := 0; i < numGoroutines; i++ {
@@ -122,93 +74,82 @@ func Test_pathTable_Store_Concurrent(t *testing.T) {
 		go func(i int) {
 			defer wg.Done()
 
-			fullPath := filepath.Join(fmt.Sprintf("full%d", i), "path")
-
-			// Use PATHTABLE.Store to store paths concurrently
-			PATHTABLE.Store(fullPath)
+			fullPath := filepath.Join(fmt.Sprintf("full%d", i/2), "path")
+			pt.Store(fullPath)
 		}(i)
 	}
 
 	// Wait for all goroutines to complete
 	wg.Wait()
 
-	// Check the total number of stored paths in the pathTable
-	pt := GetPathTableInstance() // Get the singleton instance
-	pt.mu.RLock()                // Use read lock since we are only reading data
-	defer pt.mu.RUnlock()
-
-	if len(pt.paths) != expectedPaths {
-		t.Errorf("Concurrent Store failed: expected %d paths, got %d", expectedPaths, len(pt.paths))
+	if pt.Len() != expectedPaths {
+		t.Errorf("Concurrent Store failed: expected %d paths, got %d", expectedPaths, pt.Len())
 	}
 }
 
-func Test_pathTable_LookupFullpath(t *testing.T) {
-	resetPathTable()
+func TestPathTable_LookupFullpath(t *testing.T) {
+	pt := NewPathTable()
 
 	// Store some paths for testing.
-	PATHTABLE.Store(filepath.Join("full1", "path"))
-	PATHTABLE.Store(filepath.Join("full2", "path"))
+	first := pt.Store(filepath.Join("full1", "path"))
+	second := pt.Store(filepath.Join("full2", "path"))
+	other := NewPathTable().Store(filepath.Join("other", "path"))
 
-	type args struct {
-		index PathTableIndex
-	}
 	tests := []struct {
 		name    string
-		args    args
+		index   PathTableIndex
 		want    string
-		wantErr bool
+		wantErr error
 	}{
 		{
-			name: "Lookup first full path",
-			args: args{
-				index: PathTableIndex{index: 0},
-			},
-			want:    filepath.Join("full1", "path"),
-			wantErr: false,
+			name:  "Lookup first full path",
+			index: first,
+			want:  filepath.Join("full1", "path"),
+		},
+		{
+			name:  "Lookup second full path",
+			index: second,
+			want:  filepath.Join("full2", "path"),
 		},
 		{
-			name: "Lookup second full path",
-			args: args{
-				index: PathTableIndex{index: 1},
-			},
-			want:    filepath.Join("full2", "path"),
-			wantErr: false,
+			name:    "Lookup out of bounds",
+			index:   PathTableIndex{table: pt, index: 2},
+			wantErr: ErrIndexOutOfBounds,
 		},
 		{
-			name: "Lookup out of bounds",
-			args: args{
-				index: PathTableIndex{index: 2},
-			},
-			want:    "",
-			wantErr: true,
+			name:    "Lookup index of another table",
+			index:   other,
+			wantErr: ErrOtherPathTable,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := PATHTABLE.LookupFullpath(tt.args.index)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("pathTable.LookupFullpath() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := pt.LookupFullpath(tt.index)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("PathTable.LookupFullpath() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("pathTable.LookupFullpath() = %v, want %v", got, tt.want)
+				t.Errorf("PathTable.LookupFullpath() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_pathTable_Concurrent_Read(t *testing.T) {
-	// Reset the singleton before the test starts
-	resetPathTable()
+func TestPathTableIndex_Fullpath(t *testing.T) {
+	index := NewPathTable().Store(filepath.Join("full", "path"))
+	if got, err := index.Fullpath(); err != nil || got != filepath.Join("full", "path") {
+		t.Errorf("Fullpath() = %q, %v, want the stored path", got, err)
+	}
 
-	// Store some paths for testing.
-	PATHTABLE.Store(filepath.Join("full1", "path"))
-	PATHTABLE.Store(filepath.Join("full2", "path"))
-	PATHTABLE.Store(filepath.Join("full3", "path"))
+	// The zero index belongs to no table
+	if _, err := (PathTableIndex{}).Fullpath(); !errors.Is(err, ErrIndexOutOfBounds) {
+		t.Errorf("Fullpath() of the zero index error = %v, want %v", err, ErrIndexOutOfBounds)
+	}
+}
 
-	// Number of goroutines to read concurrently
-	numGoroutines := 10
-	var wg sync.WaitGroup
+func TestPathTable_Concurrent_Read(t *testing.T) {
+	pt := NewPathTable()
 
 	// Expected values
 	expectedPaths := []string{
@@ -217,6 +158,16 @@ func Test_pathTable_Concurrent_Read(t *testing.T) {
 		filepath.Join("full3", "path"),
 	}
 
+	// Store some paths for testing.
+	var indices []PathTableIndex
+	for _, path := range expectedPaths {
+		indices = append(indices, pt.Store(path))
+	}
+
+	// Number of goroutines to read concurrently
+	numGoroutines := 10
+	var wg sync.WaitGroup
+
 	// Run concurrent readers
 	for i := 0; i < numGoroutines; i++ {
 		wg.Add(1)
@@ -224,17 +175,17 @@ func Test_pathTable_Concurrent_Read(t *testing.T) {
 			defer wg.Done()
 
 			// Cyclically pick one of the stored indexes (0, 1, or 2)
-			idx := PathTableIndex{index: uint32(i % 3)}
+			idx := indices[i%3]
 
 			// Read from the path table
-			localPath, err := PATHTABLE.LookupFullpath(idx)
+			localPath, err := pt.LookupFullpath(idx)
 			if err != nil {
 				t.Errorf("Error reading path at index %d: %v", idx.index, err)
 				return
 			}
 
 			// Check that the path read matches the expected path
-			expectedPath := expectedPaths[idx.index]
+			expectedPath := expectedPaths[i%3]
 			if localPath != expectedPath {
 				t.Errorf("Expected path %v, but got %v", expectedPath, localPath)
 			}
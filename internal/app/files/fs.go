@@ -0,0 +1,102 @@
+package files
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files are read through an fs.FS, so that a project can come from the disk, from a .zip archive of a mod,
+// from memory in tests, or from the unsaved buffers of an editor laid over the disk, see Overlay.
+// A nil fs.FS stands for the disk, where paths are paths of the OS; in any other file system
+// paths are slash-separated, as io/fs expects.
+
+// diskFS is a folder of the disk, see os.DirFS. Its files are the ones --fix may write, see OnDisk.
+type diskFS struct {
+	fs.FS
+}
+
+func (d diskFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(d.FS, name)
+}
+
+func (d diskFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(d.FS, name)
+}
+
+func (d diskFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(d.FS, name)
+}
+
+// OnDisk reports whether the file at name of fsys is the file of the disk at its full path,
+// and not a file of an archive, of memory, or an unsaved buffer of an overlay
+func OnDisk(fsys fs.FS, name string) bool {
+	switch fsys := fsys.(type) {
+	case nil, diskFS:
+		return true
+	case *Overlay:
+		if _, ok := fsys.files[name]; ok {
+			return false
+		}
+		return OnDisk(fsys.base, name)
+	default:
+		return false
+	}
+}
+
+// Stat returns the file info of the file or folder at the path of fsys
+func Stat(fsys fs.FS, name string) (fs.FileInfo, error) {
+	if fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(fsys, name)
+}
+
+// OpenFolder returns the file system of a folder of the game or of a mod, whose paths are relative to the folder.
+// A root ending in .zip is read as a zip archive of the mod, all at once, so nothing needs closing.
+func OpenFolder(fsys fs.FS, root string) (fs.FS, error) {
+	if strings.EqualFold(filepath.Ext(root), ".zip") {
+		var content []byte
+		var err error
+		if fsys == nil {
+			content, err = os.ReadFile(root)
+		} else {
+			content, err = fs.ReadFile(fsys, root)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, fmt.Errorf("reading archive %s: %w", root, err)
+		}
+		return archive, nil
+	}
+
+	if fsys == nil {
+		return diskFS{os.DirFS(root)}, nil
+	}
+	return fs.Sub(fsys, root)
+}
+
+// OpenFileEntry returns the entry of a file given by its path, such as a mod descriptor, in fsys or on the disk if fsys is nil.
+// Its path is stored in paths, or in a table of its own if paths is nil.
+func OpenFileEntry(paths *PathTable, fsys fs.FS, fullpath string, kind FileKind) (*FileEntry, error) {
+	if fsys != nil {
+		return NewFileEntryFS(paths, fsys, fullpath, fullpath, kind)
+	}
+
+	entry, err := NewFileEntryFS(paths, diskFS{os.DirFS(filepath.Dir(fullpath))}, filepath.Base(fullpath), fullpath, kind)
+	// Errors name the file by its path on the disk, not its name in the folder
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		pathErr.Path = fullpath
+	}
+	return entry, err
+}
@@ -0,0 +1,192 @@
+package files
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// zipArchive returns a zip archive of the files
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestScanPlaysetFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"game/common/traits/00_traits.txt":  {Data: []byte("vanilla")},
+		"game/common/traits/01_traits.txt":  {Data: []byte("vanilla")},
+		"first/common/traits/00_traits.txt": {Data: []byte("first")},
+		"second.zip": {Data: zipArchive(t, map[string]string{
+			"common/traits/01_traits.txt": "second",
+			"descriptor.mod":              "name = second",
+		})},
+	}
+
+	game, err := OpenFolder(fsys, "game")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := NewModLoader("First", "first", nil)
+	if first.FS, err = OpenFolder(fsys, "first"); err != nil {
+		t.Fatal(err)
+	}
+	second := NewModLoader("Second", "second.zip", nil)
+	if second.FS, err = OpenFolder(fsys, "second.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := NewPathTable()
+	entries, err := ScanPlaysetFS(paths, game, "game", first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		Path     string
+		FullPath string
+		Content  string
+	}
+	want := []entry{
+		{"common/traits/00_traits.txt", filepath.Join("first", "common", "traits", "00_traits.txt"), "first"},
+		{"common/traits/01_traits.txt", filepath.Join("second.zip", "common", "traits", "01_traits.txt"), "second"},
+	}
+
+	var got []entry
+	for _, e := range entries {
+		content, err := e.ReadFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, entry{e.Path(), e.FullPath(), string(content)})
+
+		// Locations read the file from its file system too
+		idx := e.StoreInPathTable()
+		if fullpath, _ := idx.Fullpath(); fullpath != e.FullPath() {
+			t.Errorf("Fullpath() = %q, want %q", fullpath, e.FullPath())
+		}
+		if stored, err := idx.ReadFile(); err != nil || string(stored) != string(content) {
+			t.Errorf("ReadFile() = %q, %v, want %q", stored, err, content)
+		}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanPlaysetFS() = %+v, want %+v", got, want)
+	}
+	if paths.Len() != len(want) {
+		t.Errorf("the path table has %d paths, want the %d files", paths.Len(), len(want))
+	}
+}
+
+func TestOpenFolder_Errors(t *testing.T) {
+	fsys := fstest.MapFS{"broken.zip": {Data: []byte("not a zip")}}
+
+	if _, err := OpenFolder(fsys, "broken.zip"); err == nil {
+		t.Errorf("OpenFolder() of a broken archive succeeded")
+	}
+	if _, err := OpenFolder(fsys, "missing.zip"); err == nil {
+		t.Errorf("OpenFolder() of a missing archive succeeded")
+	}
+}
+
+func TestOpenFileEntry(t *testing.T) {
+	fsys := fstest.MapFS{"mod/my.mod": {Data: []byte("name = mine")}}
+
+	entry, err := OpenFileEntry(nil, fsys, "mod/my.mod", Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := entry.ReadFile(); err != nil || string(content) != "name = mine" {
+		t.Errorf("ReadFile() = %q, %v", content, err)
+	}
+
+	if _, err := OpenFileEntry(nil, fsys, "mod/missing.mod", Mod); err == nil {
+		t.Errorf("OpenFileEntry() of a missing file succeeded")
+	}
+
+	// A missing file on the disk is named by its path on the disk
+	missing := filepath.Join(t.TempDir(), "missing.mod")
+	var pathErr *fs.PathError
+	if _, err := OpenFileEntry(nil, nil, missing, Mod); !errors.As(err, &pathErr) || pathErr.Path != missing {
+		t.Errorf("OpenFileEntry() of a missing file on the disk error = %v, want the path %s", err, missing)
+	}
+}
+
+func TestOnDisk(t *testing.T) {
+	folder, err := OpenFolder(nil, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := fstest.MapFS{"a.txt": {Data: []byte("a = b")}}
+
+	tests := []struct {
+		name string
+		fsys fs.FS
+		want bool
+	}{
+		{"disk", nil, true},
+		{"folder of the disk", folder, true},
+		{"memory", memory, false},
+		{"unsaved buffer", NewOverlay(folder, map[string][]byte{"a.txt": []byte("a = c")}), false},
+		{"saved file under an overlay", NewOverlay(folder, map[string][]byte{"b.txt": []byte("a = c")}), true},
+		{"file of memory under an overlay", NewOverlay(memory, map[string][]byte{"b.txt": []byte("a = c")}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OnDisk(tt.fsys, "a.txt"); got != tt.want {
+				t.Errorf("OnDisk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	base := fstest.MapFS{
+		"common/traits/00_traits.txt": {Data: []byte("saved")},
+		"common/traits/01_traits.txt": {Data: []byte("saved")},
+	}
+	overlay := NewOverlay(base, map[string][]byte{
+		"common/traits/00_traits.txt":       []byte("unsaved"),
+		"common/traits/02_traits.txt":       []byte("new"),
+		"history/characters/characters.txt": []byte("new folder"),
+	})
+
+	if err := fstest.TestFS(overlay,
+		"common/traits/00_traits.txt",
+		"common/traits/01_traits.txt",
+		"common/traits/02_traits.txt",
+		"history/characters/characters.txt",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"common/traits/00_traits.txt":       "unsaved",
+		"common/traits/01_traits.txt":       "saved",
+		"history/characters/characters.txt": "new folder",
+	} {
+		if content, err := fs.ReadFile(overlay, name); err != nil || string(content) != want {
+			t.Errorf("ReadFile(%q) = %q, %v, want %q", name, content, err, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package files
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Overlay is a file system that reads some files from memory and the others from a base file system,
+// such as the unsaved buffers of an editor over the folder of a mod.
+// Files of the overlay missing from the base, and the folders they are in, are listed too.
+type Overlay struct {
+	base  fs.FS
+	files map[string][]byte
+}
+
+// NewOverlay lays the files, by their slash-separated path in base, over base
+func NewOverlay(base fs.FS, files map[string][]byte) *Overlay {
+	return &Overlay{base: base, files: files}
+}
+
+func (o *Overlay) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if content, ok := o.files[name]; ok {
+		return &overlayFile{info: overlayInfo{name: pathBase(name), size: int64(len(content))}, Reader: bytes.NewReader(content)}, nil
+	}
+
+	file, err := o.base.Open(name)
+	if !o.isFolder(name) {
+		return file, err
+	}
+
+	// The folder lists the files of the overlay too
+	var info fs.FileInfo = overlayInfo{name: pathBase(name), dir: true}
+	if err == nil {
+		defer file.Close()
+		if info, err = file.Stat(); err != nil {
+			return nil, err
+		}
+	}
+	return &overlayFolder{overlay: o, name: name, info: info}, nil
+}
+
+// ReadDir lists the files of the base and of the overlay in the folder, sorted by name
+func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(o.base, name)
+	if err != nil && !o.isFolder(name) {
+		return nil, err
+	}
+
+	byName := make(map[string]fs.DirEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.Name()] = entry
+	}
+
+	for file, content := range o.files {
+		rest, ok := inFolder(name, file)
+		if !ok {
+			continue
+		}
+		if first, _, nested := strings.Cut(rest, "/"); nested {
+			if _, exists := byName[first]; !exists {
+				byName[first] = fs.FileInfoToDirEntry(overlayInfo{name: first, dir: true})
+			}
+		} else {
+			byName[first] = fs.FileInfoToDirEntry(overlayInfo{name: first, size: int64(len(content))})
+		}
+	}
+
+	result := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+	return result, nil
+}
+
+// isFolder reports whether files of the overlay are in the folder
+func (o *Overlay) isFolder(name string) bool {
+	for file := range o.files {
+		if _, ok := inFolder(name, file); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder returns the path of the file relative to the folder, if it is in it
+func inFolder(folder string, file string) (string, bool) {
+	if folder == "." {
+		return file, true
+	}
+	return strings.CutPrefix(file, folder+"/")
+}
+
+func pathBase(name string) string {
+	if name == "." {
+		return "."
+	}
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// overlayFile is a file of the overlay
+type overlayFile struct {
+	info overlayInfo
+	*bytes.Reader
+}
+
+func (f *overlayFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *overlayFile) Close() error               { return nil }
+
+// overlayFolder is a folder that only the overlay has
+type overlayFolder struct {
+	overlay *Overlay
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (f *overlayFolder) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *overlayFolder) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+}
+
+func (f *overlayFolder) Close() error { return nil }
+
+func (f *overlayFolder) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !f.read {
+		entries, err := f.overlay.ReadDir(f.name)
+		if err != nil {
+			return nil, err
+		}
+		f.entries, f.read = entries, true
+	}
+
+	if n <= 0 {
+		entries := f.entries
+		f.entries = nil
+		return entries, nil
+	}
+	if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(f.entries))
+	entries := f.entries[:n]
+	f.entries = f.entries[n:]
+	return entries, nil
+}
+
+// overlayInfo is the file info of a file or folder of the overlay
+type overlayInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i overlayInfo) Name() string       { return i.name }
+func (i overlayInfo) Size() int64        { return i.size }
+func (i overlayInfo) ModTime() time.Time { return time.Time{} }
+func (i overlayInfo) IsDir() bool        { return i.dir }
+func (i overlayInfo) Sys() any           { return nil }
+
+func (i overlayInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
@@ -0,0 +1,252 @@
+// Package fixer applies the fixes of diagnostics to the files they point at.
+package fixer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/unLomTrois/gock3/internal/app/diff"
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/utils"
+	"github.com/unLomTrois/gock3/pkg/report"
+)
+
+// File is a file changed by fixes
+type File struct {
+	Path string
+	Old  []byte
+	New  []byte
+}
+
+// Diff returns the changes of the file as a unified diff
+func (f *File) Diff() string {
+	return diff.Unified(f.Path, f.Path, string(f.Old), string(f.New))
+}
+
+// Write replaces the content of the file on disk, keeping its permissions
+func (f *File) Write() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(f.Path, f.New, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	return nil
+}
+
+// Result is what applying the fixes of diagnostics would change
+type Result struct {
+	// Files are the changed files, sorted by path
+	Files []*File
+	// Fixed are the diagnostics solved by one of their fixes
+	Fixed []*report.DiagnosticItem
+	// Remaining are the diagnostics without fixes, and the ones whose fixes conflict with an earlier fix
+	Remaining []*report.DiagnosticItem
+}
+
+// Apply works out the changes made by the fixes of the diagnostics, without writing anything.
+// Diagnostics are taken in order, and the first of their fixes that doesn't overlap
+// the edits taken so far is used. The files are only changed inside the edited ranges.
+// Files of the game are never changed, only the ones of the mod on the disk.
+func Apply(items []*report.DiagnosticItem) (*Result, error) {
+	a := &applier{sources: make(map[string]*source)}
+	result := &Result{}
+
+	for _, item := range items {
+		fixed := false
+		for _, fix := range item.Fixes {
+			ok, err := a.take(fix)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				fixed = true
+				break
+			}
+		}
+
+		if fixed {
+			result.Fixed = append(result.Fixed, item)
+		} else {
+			result.Remaining = append(result.Remaining, item)
+		}
+	}
+
+	for path, src := range a.sources {
+		if len(src.edits) == 0 {
+			continue
+		}
+		result.Files = append(result.Files, &File{Path: path, Old: src.original, New: src.apply()})
+	}
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Path < result.Files[j].Path
+	})
+
+	return result, nil
+}
+
+// edit is a TextEdit resolved against the content of its file
+type edit struct {
+	start, end int
+	text       string
+}
+
+// source is a file and the edits taken for it
+type source struct {
+	original []byte
+	// content is the original without the byte order mark, which offsets don't count
+	content []byte
+	edits   []edit
+}
+
+type applier struct {
+	sources map[string]*source
+}
+
+// take adds the edits of the fix if none of them overlaps an edit taken before
+func (a *applier) take(fix *report.Fix) (bool, error) {
+	type pending struct {
+		src  *source
+		edit edit
+	}
+	var edits []pending
+
+	if !fix.Applicable() {
+		return false, nil
+	}
+
+	for _, textEdit := range fix.Edits {
+		path, err := textEdit.Loc.Pathname()
+		if err != nil {
+			return false, nil
+		}
+
+		src, err := a.source(path, textEdit.Loc.GetIdx())
+		if err != nil {
+			return false, err
+		}
+
+		e, ok := src.resolve(textEdit)
+		if !ok {
+			return false, nil
+		}
+
+		for _, other := range edits {
+			if other.src == src && overlaps(other.edit, e) {
+				return false, nil
+			}
+		}
+		edits = append(edits, pending{src, e})
+	}
+
+	var added []pending
+	for _, p := range edits {
+		duplicate, conflict := p.src.check(p.edit)
+		if conflict {
+			return false, nil
+		}
+		if !duplicate {
+			added = append(added, p)
+		}
+	}
+
+	for _, p := range added {
+		p.src.edits = append(p.src.edits, p.edit)
+	}
+	return true, nil
+}
+
+func (a *applier) source(path string, idx files.PathTableIndex) (*source, error) {
+	if src, ok := a.sources[path]; ok {
+		return src, nil
+	}
+
+	original, err := idx.ReadFile()
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	content, _ := utils.SplitUTF8BOM(original)
+
+	src := &source{original: original, content: content}
+	a.sources[path] = src
+	return src, nil
+}
+
+// resolve checks that the edit is inside the file.
+// A deletion of everything on a line but whitespace is widened to the whole line.
+func (s *source) resolve(textEdit *report.TextEdit) (edit, bool) {
+	e := edit{start: textEdit.Span.Start, end: textEdit.Span.End, text: textEdit.NewText}
+	if e.start < 0 || e.end < e.start || e.end > len(s.content) {
+		return edit{}, false
+	}
+
+	if e.text != "" {
+		return e, true
+	}
+
+	lineStart := bytes.LastIndexByte(s.content[:e.start], '\n') + 1
+	lineEnd := len(s.content)
+	if i := bytes.IndexByte(s.content[e.end:], '\n'); i >= 0 {
+		lineEnd = e.end + i + 1
+	}
+
+	if isBlank(s.content[lineStart:e.start]) && isBlank(s.content[e.end:lineEnd]) {
+		e.start, e.end = lineStart, lineEnd
+	}
+
+	return e, true
+}
+
+// check reports whether the edit was already taken, or overlaps one that was
+func (s *source) check(e edit) (duplicate bool, conflict bool) {
+	for _, other := range s.edits {
+		if other == e {
+			return true, false
+		}
+		if overlaps(other, e) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// apply returns the content of the file with the edits made
+func (s *source) apply() []byte {
+	edits := make([]edit, len(s.edits))
+	copy(edits, s.edits)
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	var out bytes.Buffer
+	// The byte order mark is kept as it was
+	out.Write(s.original[:len(s.original)-len(s.content)])
+
+	position := 0
+	for _, e := range edits {
+		out.Write(s.content[position:e.start])
+		out.WriteString(e.text)
+		position = e.end
+	}
+	out.Write(s.content[position:])
+
+	return out.Bytes()
+}
+
+// overlaps reports whether two edits touch the same bytes.
+// Edits that replace nothing conflict when they are at the same place, since their order is unknown.
+func overlaps(a, b edit) bool {
+	if a.start == b.start {
+		return true
+	}
+	return a.start < b.end && b.start < a.end
+}
+
+func isBlank(text []byte) bool {
+	return len(bytes.TrimLeft(text, " \t\r\n")) == 0
+}
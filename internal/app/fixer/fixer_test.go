@@ -0,0 +1,213 @@
+package fixer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// span returns the range of the first occurrence of text in content
+func span(t *testing.T, content string, text string) tokens.Span {
+	t.Helper()
+
+	start := strings.Index(content, text)
+	if start < 0 {
+		t.Fatalf("%q is not in %q", text, content)
+	}
+	return tokens.Span{Start: start, End: start + len(text)}
+}
+
+// testLoc returns the location of the start of the file at path on the disk
+func testLoc(t *testing.T, path string, kind files.FileKind) tokens.Loc {
+	t.Helper()
+
+	entry, err := files.OpenFileEntry(nil, nil, path, kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *tokens.LocFromFileEntry(entry)
+}
+
+func TestApply(t *testing.T) {
+	const content = "a == {\n\tversion = 1.0\n\tbanned = 5 # why\n\tgone = yes\n}\n"
+
+	tests := []struct {
+		name string
+		// edits has a fix per diagnostic, of the text to replace and its replacement
+		edits [][2]string
+		want  string
+		fixed int
+	}{
+		{
+			name:  "replace a token",
+			edits: [][2]string{{"==", "="}},
+			want:  "a = {\n\tversion = 1.0\n\tbanned = 5 # why\n\tgone = yes\n}\n",
+			fixed: 1,
+		},
+		{
+			name:  "several edits",
+			edits: [][2]string{{"==", "="}, {"1.0", `"1.0"`}},
+			want:  "a = {\n\tversion = \"1.0\"\n\tbanned = 5 # why\n\tgone = yes\n}\n",
+			fixed: 2,
+		},
+		{
+			name:  "delete the only field of a line",
+			edits: [][2]string{{"gone = yes", ""}},
+			want:  "a == {\n\tversion = 1.0\n\tbanned = 5 # why\n}\n",
+			fixed: 1,
+		},
+		{
+			name:  "delete a field followed by a comment",
+			edits: [][2]string{{"banned = 5", ""}},
+			want:  "a == {\n\tversion = 1.0\n\t # why\n\tgone = yes\n}\n",
+			fixed: 1,
+		},
+		{
+			name:  "overlapping edits",
+			edits: [][2]string{{"version = 1.0", ""}, {"1.0", `"1.0"`}},
+			want:  "a == {\n\tbanned = 5 # why\n\tgone = yes\n}\n",
+			fixed: 1,
+		},
+		{
+			name:  "the same edit twice",
+			edits: [][2]string{{"==", "="}, {"==", "="}},
+			want:  "a = {\n\tversion = 1.0\n\tbanned = 5 # why\n\tgone = yes\n}\n",
+			fixed: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.txt")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			loc := testLoc(t, path, files.Mod)
+
+			var items []*report.DiagnosticItem
+			for _, e := range tt.edits {
+				edit := &report.TextEdit{Loc: loc, Span: span(t, content, e[0]), NewText: e[1]}
+				items = append(items, report.FromLoc(loc, rules.ParseMissingValue, "problem").WithFix("fix", edit))
+			}
+			items = append(items, report.FromLoc(loc, rules.ParseMissingValue, "without a fix"))
+
+			result, err := Apply(items)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Files) != 1 {
+				t.Fatalf("Apply() changed %d files, want 1", len(result.Files))
+			}
+			if got := string(result.Files[0].New); got != tt.want {
+				t.Errorf("New =\n%q\nwant\n%q", got, tt.want)
+			}
+			if len(result.Fixed) != tt.fixed || len(result.Remaining) != len(items)-tt.fixed {
+				t.Errorf("fixed %d and left %d diagnostics, want %d and %d", len(result.Fixed), len(result.Remaining), tt.fixed, len(items)-tt.fixed)
+			}
+
+			// Nothing is written until asked
+			if disk, _ := os.ReadFile(path); string(disk) != content {
+				t.Errorf("Apply() wrote the file")
+			}
+		})
+	}
+}
+
+func TestApply_ByteOrderMark(t *testing.T) {
+	const content = "\uFEFFa == b\r\nc = d\r\n"
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loc := testLoc(t, path, files.Mod)
+
+	// Offsets don't count the byte order mark
+	edit := &report.TextEdit{Loc: loc, Span: tokens.Span{Start: 2, End: 4}, NewText: "="}
+	result, err := Apply([]*report.DiagnosticItem{report.FromLoc(loc, rules.ParseMissingValue, "problem").WithFix("fix", edit)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "\uFEFFa = b\r\nc = d\r\n"; string(result.Files[0].New) != want {
+		t.Fatalf("New = %q, want %q", result.Files[0].New, want)
+	}
+	if diff := result.Files[0].Diff(); !strings.Contains(diff, "-\uFEFFa == b") || !strings.Contains(diff, "+\uFEFFa = b") {
+		t.Errorf("Diff() =\n%s", diff)
+	}
+
+	if err := result.Files[0].Write(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if disk, _ := os.ReadFile(path); string(disk) != string(result.Files[0].New) || info.Mode().Perm() != 0o600 {
+		t.Errorf("Write() wrote %q with mode %v", disk, info.Mode().Perm())
+	}
+}
+
+func TestApply_Unfixable(t *testing.T) {
+	// unsaved lays an unsaved buffer over the file in the folder
+	unsaved := func(t *testing.T, dir string) fs.FS {
+		folder, err := files.OpenFolder(nil, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files.NewOverlay(folder, map[string][]byte{"test.txt": []byte("a = b")})
+	}
+	inMemory := func(t *testing.T, dir string) fs.FS {
+		return fstest.MapFS{"test.txt": {Data: []byte("a = b")}}
+	}
+
+	tests := []struct {
+		name string
+		kind files.FileKind
+		span tokens.Span
+		// fsys returns the file system the file is read from instead of the disk, given its folder
+		fsys func(t *testing.T, dir string) fs.FS
+	}{
+		{"edit outside the file", files.Mod, tokens.Span{Start: 3, End: 50}, nil},
+		{"file of the game", files.Vanilla, tokens.Span{Start: 4, End: 5}, nil},
+		{"unsaved buffer", files.Mod, tokens.Span{Start: 4, End: 5}, unsaved},
+		{"file in memory", files.Mod, tokens.Span{Start: 4, End: 5}, inMemory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "test.txt")
+			if err := os.WriteFile(path, []byte("a = b"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			loc := testLoc(t, path, tt.kind)
+			if tt.fsys != nil {
+				entry, err := files.NewFileEntryFS(nil, tt.fsys(t, dir), "test.txt", path, tt.kind)
+				if err != nil {
+					t.Fatal(err)
+				}
+				loc = *tokens.LocFromFileEntry(entry)
+			}
+
+			edit := &report.TextEdit{Loc: loc, Span: tt.span, NewText: "c"}
+			result, err := Apply([]*report.DiagnosticItem{report.FromLoc(loc, rules.ParseMissingValue, "problem").WithFix("fix", edit)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Files) != 0 || len(result.Remaining) != 1 {
+				t.Errorf("Apply() changed %d files and left %d diagnostics, want 0 and 1", len(result.Files), len(result.Remaining))
+			}
+		})
+	}
+}
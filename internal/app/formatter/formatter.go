@@ -0,0 +1,327 @@
+// Package formatter prints script files in a canonical layout.
+//
+// The formatter works on the concrete syntax tree, so comments stay where they were written.
+// It indents blocks with tabs, puts every field on its own line, separates keys, operators and values
+// with single spaces, and keeps at most one blank line between items. Lists of values written on a single line,
+// such as `color = { 255 0 0 }`, stay on a single line; blocks of fields such as `NOT = { has_trait = brave }` don't.
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser"
+	"github.com/unLomTrois/gock3/internal/app/parser/cst"
+	"github.com/unLomTrois/gock3/internal/app/utils"
+	"github.com/unLomTrois/gock3/pkg/report"
+)
+
+// SyntaxError is returned for files that can't be formatted because they don't parse cleanly
+type SyntaxError struct {
+	Diagnostics []*report.DiagnosticItem
+}
+
+func (e *SyntaxError) Error() string {
+	first := e.Diagnostics[0]
+	return fmt.Sprintf("%d:%d: %s (%d problems in total)", first.Pointer.Loc.Line, first.Pointer.Loc.Column, first.Msg, len(e.Diagnostics))
+}
+
+// Source formats the content of a script file.
+// Files with syntax errors are left alone, since there is no telling what the author meant.
+// The result is checked to contain the same tokens and comments as the input.
+func Source(entry *files.FileEntry, content []byte) ([]byte, error) {
+	text, bom := utils.SplitUTF8BOM(content)
+
+	stream, errs := lexer.ScanWithOptions(entry, text, lexer.Options{KeepTrivia: true})
+	tree := &cst.Tree{BOM: bom, Root: parser.ParseCST(stream)}
+
+	// The parser unquotes strings in place, so it gets a token stream of its own
+	plain, _ := lexer.Scan(entry, text)
+	_, parserErrs := parser.Parse(plain)
+	errs = append(errs, parserErrs...)
+	if len(errs) > 0 {
+		return nil, &SyntaxError{Diagnostics: errs}
+	}
+
+	formatted := Format(tree)
+
+	formattedText, _ := utils.SplitUTF8BOM(formatted)
+	formattedStream, _ := lexer.ScanWithOptions(entry, formattedText, lexer.Options{KeepTrivia: true})
+	if err := compareTokens(stream.Tokens, formattedStream.Tokens); err != nil {
+		return nil, fmt.Errorf("formatting would change the file: %w", err)
+	}
+
+	return formatted, nil
+}
+
+// compareTokens checks that two token streams differ only in whitespace and the placement of comments
+func compareTokens(before []*tokens.Token, after []*tokens.Token) error {
+	if err := compareSequences(significant(before), significant(after)); err != nil {
+		return err
+	}
+	return compareSequences(comments(before), comments(after))
+}
+
+func compareSequences(before []*tokens.Token, after []*tokens.Token) error {
+	for i := range min(len(before), len(after)) {
+		if tokenText(before[i]) != tokenText(after[i]) {
+			return fmt.Errorf("%q became %q at %d:%d", before[i].Value, after[i].Value, before[i].Loc.Line, before[i].Loc.Column)
+		}
+	}
+	if len(before) != len(after) {
+		return fmt.Errorf("got %d tokens, want %d", len(after), len(before))
+	}
+
+	return nil
+}
+
+func significant(all []*tokens.Token) []*tokens.Token {
+	var result []*tokens.Token
+	for _, token := range all {
+		if !cst.IsTrivia(token.Type) {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+func comments(all []*tokens.Token) []*tokens.Token {
+	var result []*tokens.Token
+	for _, token := range all {
+		if token.Type == tokens.COMMENT {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+func tokenText(token *tokens.Token) string {
+	if token.Type == tokens.COMMENT {
+		return commentText(token)
+	}
+	return token.Value
+}
+
+// commentText returns the comment without trailing whitespace, which the formatter drops
+func commentText(token *tokens.Token) string {
+	return strings.TrimRight(token.Value, " \t\r\f")
+}
+
+// Format prints the tree in the canonical layout.
+// Line endings follow the first line ending of the file, and the byte order mark is kept.
+func Format(tree *cst.Tree) []byte {
+	p := &printer{newline: "\n"}
+	for _, token := range tree.Root.Tokens() {
+		if token.Type == tokens.NEXTLINE {
+			p.newline = token.Value
+			break
+		}
+	}
+
+	if tree.BOM {
+		p.buf.WriteString("\uFEFF")
+	}
+
+	p.items(tree.Root.Children)
+	p.endLine()
+
+	return p.buf.Bytes()
+}
+
+type printer struct {
+	buf     bytes.Buffer
+	newline string
+	depth   int
+
+	// inLine is whether the current line has been started and not ended yet
+	inLine bool
+	// started is whether any line has been printed
+	started bool
+	// pending holds comments found inside a field, which are printed after its value
+	pending []*tokens.Token
+}
+
+func (p *printer) startLine(blank bool) {
+	p.endLine()
+	if blank && p.started {
+		p.buf.WriteString(p.newline)
+	}
+	p.buf.WriteString(strings.Repeat("\t", p.depth))
+	p.inLine = true
+	p.started = true
+}
+
+func (p *printer) endLine() {
+	if p.inLine {
+		p.buf.WriteString(p.newline)
+		p.inLine = false
+	}
+}
+
+func (p *printer) write(text string) {
+	p.buf.WriteString(text)
+}
+
+// comment prints a comment at the end of the current line, or on a line of its own
+func (p *printer) comment(token *tokens.Token, trailing bool, blank bool) {
+	if trailing && p.inLine {
+		p.write(" ")
+	} else {
+		p.startLine(blank)
+	}
+	p.write(commentText(token))
+	p.endLine()
+}
+
+// flushComments prints the comments found inside a field after what has been printed of it
+func (p *printer) flushComments() {
+	for i, token := range p.pending {
+		p.comment(token, i == 0, false)
+	}
+	p.pending = nil
+}
+
+// items prints the fields, values and comments of a file or of a block spanning several lines.
+// Every field starts a new line, while values written on the same line stay together.
+func (p *printer) items(nodes []*cst.Node) {
+	newlines := 0
+	first := true
+	previousValue := false
+
+	for _, node := range nodes {
+		if node.Kind == cst.KindToken {
+			switch node.Token.Type {
+			case tokens.NEXTLINE:
+				newlines++
+				continue
+			case tokens.WHITESPACE, tokens.TAB:
+				continue
+			case tokens.COMMENT:
+				p.comment(node.Token, newlines == 0, !first && newlines > 1)
+				newlines, first, previousValue = 0, false, false
+				continue
+			}
+		}
+
+		isValue := node.Kind != cst.KindField
+		if isValue && previousValue && newlines == 0 && p.inLine {
+			p.write(" ")
+		} else {
+			p.startLine(!first && newlines > 1)
+		}
+
+		p.item(node)
+		newlines, first, previousValue = 0, false, isValue
+	}
+}
+
+func (p *printer) item(node *cst.Node) {
+	switch node.Kind {
+	case cst.KindField:
+		p.field(node)
+	case cst.KindBlock:
+		p.block(node)
+	default:
+		p.inline(node)
+	}
+}
+
+// field prints a key, an operator and a value separated by single spaces.
+// The value may have been written on the next line; it's joined to the key.
+func (p *printer) field(node *cst.Node) {
+	printed := 0
+	for _, child := range node.Children {
+		if child.Kind == cst.KindToken && cst.IsTrivia(child.Token.Type) {
+			if child.Token.Type == tokens.COMMENT {
+				p.pending = append(p.pending, child.Token)
+			}
+			continue
+		}
+
+		if printed > 0 {
+			p.write(" ")
+		}
+		printed++
+
+		if child.Kind == cst.KindBlock {
+			p.block(child)
+		} else {
+			p.inline(child)
+		}
+	}
+
+	p.flushComments()
+}
+
+// block prints a block, on a single line if it is a list of values written on one or is empty
+func (p *printer) block(node *cst.Node) {
+	if isInline(node) {
+		p.inline(node)
+		p.flushComments()
+		return
+	}
+
+	inner := node.Children[1:]
+	var end *cst.Node
+	if len(inner) > 0 {
+		if last := inner[len(inner)-1]; last.Kind == cst.KindToken && last.Token.Type == tokens.END {
+			inner, end = inner[:len(inner)-1], last
+		}
+	}
+
+	p.write(node.Children[0].Token.Value)
+	p.depth++
+	p.flushComments()
+	p.items(inner)
+	p.depth--
+
+	if end != nil {
+		p.startLine(false)
+		p.write(end.Token.Value)
+	}
+}
+
+// inline prints a node on the current line, with single spaces between its tokens
+func (p *printer) inline(node *cst.Node) {
+	significant := node.SignificantTokens()
+	for i, token := range significant {
+		if i > 0 {
+			p.write(" ")
+		}
+		p.write(token.Value)
+	}
+}
+
+// isInline reports whether a closed block without comments was written on a single line or is empty.
+// Only lists of values such as { 7 14 } stay inline, blocks of fields get one field per line.
+func isInline(node *cst.Node) bool {
+	all := node.Tokens()
+	if len(all) == 0 || all[len(all)-1].Type != tokens.END || hasFields(node) {
+		return false
+	}
+
+	multiline := false
+	for _, token := range all {
+		switch token.Type {
+		case tokens.COMMENT:
+			return false
+		case tokens.NEXTLINE:
+			multiline = true
+		}
+	}
+	return !multiline || len(node.SignificantTokens()) == 2
+}
+
+// hasFields reports whether the node holds a field, at any depth
+func hasFields(node *cst.Node) bool {
+	for _, child := range node.Children {
+		if child.Kind == cst.KindField || hasFields(child) {
+			return true
+		}
+	}
+	return false
+}
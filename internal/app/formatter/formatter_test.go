@@ -0,0 +1,117 @@
+package formatter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+)
+
+// fixturesDir holds sample script files shared by the tests of the whole repository
+const fixturesDir = "../../../data"
+
+// formatText formats text written to a temporary file.
+func formatText(t *testing.T, text string) ([]byte, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Source(entry, []byte(text))
+}
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"spacing", "a=b\nc   ?=   d\n", "a = b\nc ?= d\n"},
+		{"one field per line", "a = b c = d", "a = b\nc = d\n"},
+		{"value on next line", "a =\n\tb\n", "a = b\n"},
+		{"block on next line", "a =\n{\n\tb = c\n}", "a = {\n\tb = c\n}\n"},
+		{"indentation", "a = {\n    b = {\nc = d\n}\n}", "a = {\n\tb = {\n\t\tc = d\n\t}\n}\n"},
+		{"single line lists", "color = {  255 0   0 }\nlist = { { 1 2 } 3 }", "color = { 255 0 0 }\nlist = { { 1 2 } 3 }\n"},
+		{"single line blocks of fields", "a = {b=c}\ntrigger = { a = yes b = no }", "a = {\n\tb = c\n}\ntrigger = {\n\ta = yes\n\tb = no\n}\n"},
+		{"single line list of blocks of fields", "list = { { a = b } }", "list = {\n\t{\n\t\ta = b\n\t}\n}\n"},
+		{"empty blocks", "a = {}\nb = {\n\n}\n", "a = { }\nb = { }\n"},
+		{"values keep their lines", "a = {\n1 2\n  3 4 }", "a = {\n\t1 2\n\t3 4\n}\n"},
+		{"blank lines", "\n\na = b\n\n\n\nc = d\n\n", "a = b\n\nc = d\n"},
+		{"no blank line after brace", "a = {\n\n\tb = c\n\n}", "a = {\n\tb = c\n}\n"},
+		{"quoted strings", "desc = \"some  text\"", "desc = \"some  text\"\n"},
+		{"comments", "# leading\na = b   # trailing  \n\n# dangling\n", "# leading\na = b # trailing\n\n# dangling\n"},
+		{"comment after brace", "a = {  # opening\n\tb = c\n}", "a = { # opening\n\tb = c\n}\n"},
+		{"comment before value", "a = # note\n\tb", "a = b # note\n"},
+		{"comment in block", "a = { b = c\n# last\n}", "a = {\n\tb = c\n\t# last\n}\n"},
+		{"CRLF", "a = {\r\nb = c\r\n}\r\n", "a = {\r\n\tb = c\r\n}\r\n"},
+		{"byte order mark", "\ufeffa=b", "\ufeffa = b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatText(t, tt.text)
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Source() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSource_SyntaxError(t *testing.T) {
+	_, err := formatText(t, "a = {\n\tb = c\n")
+
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Source() error = %v, want a *SyntaxError", err)
+	}
+}
+
+// TestSource_Fixtures checks that formatting the sample files succeeds and is idempotent.
+func TestSource_Fixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fixturesDir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			formatted, err := Source(entry, content)
+			var syntaxErr *SyntaxError
+			if errors.As(err, &syntaxErr) {
+				t.Skipf("the fixture has syntax errors: %v", err)
+			}
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+
+			again, err := formatText(t, string(formatted))
+			if err != nil {
+				t.Fatalf("formatting the result: %v", err)
+			}
+			if string(again) != string(formatted) {
+				t.Errorf("formatting is not idempotent")
+			}
+		})
+	}
+}
@@ -2,45 +2,49 @@
 package lexer
 
 import (
-	"bytes"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/unLomTrois/gock3/internal/app/files"
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
 type Lexer struct {
-	fileEntry      *files.FileEntry
-	text           []byte
-	cursor         int
-	line           int
-	column         int
-	patternMatcher *TokenPatternMatcher
+	fileEntry *files.FileEntry
+	text      []byte
+	cursor    int
+	line      int
+	column    int
+	options   Options
+	// comments are every comment read, whether the options keep them in the stream or not
+	comments []*tokens.Token
 	*report.ErrorManager
 }
 
+// Options configures optional behaviour of the lexer.
+type Options struct {
+	// KeepComments makes the lexer emit COMMENT tokens instead of discarding them,
+	// so that the parser can attach them to the AST as trivia.
+	KeepComments bool
+	// KeepTrivia makes the lexer emit every byte of the input: comments, whitespace, tabs,
+	// and UNKNOWN tokens for unexpected characters. It is used to build the concrete syntax tree.
+	KeepTrivia bool
+}
+
 // NewLexer creates a new Lexer instance
 func NewLexer(entry *files.FileEntry, text []byte) *Lexer {
 	return &Lexer{
-		fileEntry:      entry,
-		text:           NormalizeText(text),
-		cursor:         0,
-		line:           1,
-		column:         1,
-		patternMatcher: NewTokenPatternMatcher(),
-		ErrorManager:   report.NewErrorManager(),
+		fileEntry:    entry,
+		text:         text,
+		cursor:       0,
+		line:         1,
+		column:       1,
+		ErrorManager: report.NewErrorManager(),
 	}
 }
 
-// NormalizeText trims spaces and converts CRLF to LF
-func NormalizeText(text []byte) []byte {
-	// text = bytes.TrimSpace(text)
-	text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
-	return text
-}
-
 // hasMoreTokens checks if there are more tokens to process by comparing the current cursor position with the text length
 func (lex *Lexer) hasMoreTokens() bool {
 	return lex.cursor < len(lex.text)
@@ -48,7 +52,13 @@ func (lex *Lexer) hasMoreTokens() bool {
 
 // Scan tokenizes the entire input text
 func Scan(entry *files.FileEntry, text []byte) (*tokens.TokenStream, []*report.DiagnosticItem) {
+	return ScanWithOptions(entry, text, Options{})
+}
+
+// ScanWithOptions tokenizes the entire input text using the given options
+func ScanWithOptions(entry *files.FileEntry, text []byte, options Options) (*tokens.TokenStream, []*report.DiagnosticItem) {
 	lex := NewLexer(entry, text)
+	lex.options = options
 
 	tokenStream := tokens.NewTokenStream()
 
@@ -59,6 +69,7 @@ func Scan(entry *files.FileEntry, text []byte) (*tokens.TokenStream, []*report.D
 		}
 		tokenStream.Push(token)
 	}
+	tokenStream.Comments = lex.comments
 
 	return tokenStream, lex.Errors()
 }
@@ -69,7 +80,7 @@ func (lex *Lexer) remainder() []byte {
 }
 
 // getNextToken processes and returns the next token from the input text.
-// It matches the remaining text against token patterns in a specific order,
+// It recognizes the token at the cursor with the hand-written scanner in scanner.go,
 // handles special tokens like whitespace and newlines by updating line/column numbers,
 // and returns nil for ignored tokens. If no valid token is found, it reports an error
 // and advances the cursor to prevent infinite loops.
@@ -80,69 +91,80 @@ func (lex *Lexer) getNextToken() *tokens.Token {
 
 	remainder := lex.remainder()
 
-	var matchedToken []byte
-	var matchedTokenType tokens.TokenType
-
-	// Keep track of the initial line and column for the token
+	// Keep track of the initial position of the token
 	startLine := lex.line
 	startColumn := lex.column
+	startOffset := lex.cursor
 
-	// Try to match tokens in the specified order
-	for _, tokenType := range tokens.TokenCheckOrder {
-		match := lex.patternMatcher.MatchToken(tokenType, remainder)
-		if match == nil {
-			continue
-		}
-
-		// Accept the first match
-		matchedToken = match
-		matchedTokenType = tokenType
-		break
-	}
+	matchedTokenType, length := matchToken(remainder)
 
-	if matchedToken != nil {
+	if length > 0 {
+		matchedToken := remainder[:length]
 		tokenValue := string(matchedToken)
 		lex.cursor += len(matchedToken)
+		span := tokens.Span{Start: startOffset, End: lex.cursor}
 
 		switch matchedTokenType {
-		case tokens.TAB:
-			// Consider tab width as 4 spaces
-			lex.column += 4 // Already added 1 in len(longestMatch)
-			return nil
+		case tokens.TAB, tokens.WHITESPACE:
+			// Ignore whitespace, a tab takes a single column like any other character
+			lex.column++
+			if !lex.options.KeepTrivia {
+				return nil
+			}
+			return lex.newToken(tokenValue, matchedTokenType, startLine, startColumn, startOffset, span)
 		case tokens.NEXTLINE:
 			lex.line++
 			lex.column = 1
 
-			loc := tokens.LocFromFileEntry(lex.fileEntry)
-			loc.Line = uint32(lex.line)
-			loc.Column = uint16(lex.column)
-			return tokens.New(tokenValue, matchedTokenType, *loc)
-		case tokens.WHITESPACE:
-			// Ignore whitespace
-			lex.column++
-			return nil
+			// NEXTLINE points at the start of the line it opens
+			return lex.newToken(tokenValue, matchedTokenType, lex.line, lex.column, lex.cursor, span)
 		case tokens.COMMENT:
-			// Ignore comments
-			return nil
+			lex.column += utf8.RuneCount(matchedToken)
+			// Comments are always collected, since some of them are directives such as gock3:ignore
+			comment := lex.newToken(tokenValue, matchedTokenType, startLine, startColumn, startOffset, span)
+			lex.comments = append(lex.comments, comment)
+			if !lex.options.KeepComments && !lex.options.KeepTrivia {
+				return nil
+			}
+			return comment
 		default:
-			lex.column += len(matchedToken)
-			loc := tokens.LocFromFileEntry(lex.fileEntry)
-			loc.Line = uint32(startLine)
-			loc.Column = uint16(startColumn)
-			return tokens.New(tokenValue, matchedTokenType, *loc)
+			lex.column += utf8.RuneCount(matchedToken)
+			return lex.newToken(tokenValue, matchedTokenType, startLine, startColumn, startOffset, span)
 		}
 	}
 
-	unexpectedChar := remainder[0]
-	loc := tokens.LocFromFileEntry(lex.fileEntry)
-	loc.Line = uint32(lex.line)
-	loc.Column = uint16(lex.column)
-	err := report.FromLoc(*loc, severity.Critical, fmt.Sprintf("unexpected token '%c'", unexpectedChar))
+	unexpectedChar, size := utf8.DecodeRune(remainder)
+	loc := lex.newLoc(lex.line, lex.column, lex.cursor)
+	err := report.FromLoc(*loc, rules.LexUnexpectedCharacter, fmt.Sprintf("unexpected token '%c'", unexpectedChar))
+	if unexpectedChar >= utf8.RuneSelf {
+		err.WithHelp("text with non-ASCII characters must be in a quoted string")
+	}
 	lex.AddError(err)
 
-	// Advance cursor to prevent infinite loop
-	lex.cursor++
+	// Advance cursor past the whole character to prevent infinite loop
+	lex.cursor += size
 	lex.column++
 
+	if lex.options.KeepTrivia {
+		span := tokens.Span{Start: startOffset, End: lex.cursor}
+		return lex.newToken(string(remainder[:size]), tokens.UNKNOWN, startLine, startColumn, startOffset, span)
+	}
+
 	return nil
 }
+
+// newLoc creates a Loc in the lexed file
+func (lex *Lexer) newLoc(line int, column int, offset int) *tokens.Loc {
+	loc := tokens.LocFromFileEntry(lex.fileEntry)
+	loc.Line = uint32(line)
+	loc.Column = uint32(column)
+	loc.Offset = uint32(offset)
+	return loc
+}
+
+// newToken creates a token located in the lexed file
+func (lex *Lexer) newToken(value string, tokenType tokens.TokenType, line int, column int, offset int, span tokens.Span) *tokens.Token {
+	token := tokens.New(value, tokenType, *lex.newLoc(line, column, offset))
+	token.Span = span
+	return token
+}
@@ -0,0 +1,70 @@
+package lexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+)
+
+func scanText(t *testing.T, text string) (*tokens.TokenStream, int) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, errs := Scan(entry, []byte(text))
+	return stream, len(errs)
+}
+
+func TestScan_Locations(t *testing.T) {
+	text := "key = \"имя\"\r\n\t\tname = 名前\n"
+	stream, _ := scanText(t, text)
+
+	tests := []struct {
+		value  string
+		line   uint32
+		column uint32
+	}{
+		{"key", 1, 1},
+		{"=", 1, 5},
+		{`"имя"`, 1, 7},
+		{"\r\n", 2, 1},
+		{"name", 2, 3},
+		{"=", 2, 8},
+		{"\n", 3, 1},
+	}
+
+	if len(stream.Tokens) != len(tests) {
+		t.Fatalf("got %d tokens, want %d: %v", len(stream.Tokens), len(tests), stream.Tokens)
+	}
+
+	for i, tt := range tests {
+		token := stream.Tokens[i]
+		if token.Value != tt.value {
+			t.Errorf("token %d = %q, want %q", i, token.Value, tt.value)
+		}
+		if token.Loc.Line != tt.line || token.Loc.Column != tt.column {
+			t.Errorf("token %q at %d:%d, want %d:%d", token.Value, token.Loc.Line, token.Loc.Column, tt.line, tt.column)
+		}
+		if got := text[token.Span.Start:token.Span.End]; got != token.Value {
+			t.Errorf("span of token %q covers %q", token.Value, got)
+		}
+	}
+}
+
+func TestScan_UnexpectedMultibyteCharacter(t *testing.T) {
+	// The Chinese value is not a valid word, each character must be reported once
+	_, errs := scanText(t, "name = 名前\n")
+	if errs != 2 {
+		t.Errorf("got %d errors, want 2", errs)
+	}
+}
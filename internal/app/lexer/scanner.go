@@ -0,0 +1,214 @@
+package lexer
+
+import "github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+
+// matchToken recognizes the token at the start of text and returns its type and length in bytes.
+// It is a hand-written equivalent of trying every pattern of tokens.TokenTypeRegexMap
+// in tokens.TokenCheckOrder, and returns a zero length if nothing matches.
+// Unlike the regular expressions, it also accepts CRLF line endings as a single NEXTLINE,
+// so that the text doesn't have to be rewritten and byte offsets stay valid.
+func matchToken(text []byte) (tokens.TokenType, int) {
+	if len(text) == 0 {
+		return 0, 0
+	}
+
+	switch c := text[0]; c {
+	case '\n':
+		return tokens.NEXTLINE, 1
+	case '\t':
+		return tokens.TAB, 1
+	case '\r':
+		if len(text) > 1 && text[1] == '\n' {
+			return tokens.NEXTLINE, 2
+		}
+		return tokens.WHITESPACE, 1
+	case ' ', '\f':
+		return tokens.WHITESPACE, 1
+	case '<', '>':
+		if len(text) > 1 && text[1] == '=' {
+			return tokens.COMPARISON, 2
+		}
+		return tokens.COMPARISON, 1
+	case '#':
+		return tokens.COMMENT, scanComment(text)
+	case '"':
+		return tokens.QUOTED_STRING, scanQuotedString(text)
+	case '?':
+		if len(text) > 1 && text[1] == '=' {
+			return tokens.QUESTION_EQUALS, 2
+		}
+		return 0, 0
+	case '=':
+		if len(text) > 1 && text[1] == '=' {
+			return tokens.EQUALS, 2
+		}
+		return tokens.EQUALS, 1
+	case '{':
+		return tokens.START, 1
+	case '}':
+		return tokens.END, 1
+	}
+
+	if n := scanBool(text); n > 0 {
+		return tokens.BOOL, n
+	}
+	if n := scanDate(text); n > 0 {
+		return tokens.DATE, n
+	}
+	if n := scanNumber(text); n > 0 {
+		return tokens.NUMBER, n
+	}
+	if n := scanWord(text); n > 0 {
+		return tokens.WORD, n
+	}
+
+	return 0, 0
+}
+
+// isDigit reports whether c matches \d.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isWordChar reports whether c matches \w.
+func isWordChar(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c == '_'
+}
+
+// isWordBoundary reports whether \b holds at position i of text, given that text[i-1] is a word character.
+func isWordBoundary(text []byte, i int) bool {
+	return i >= len(text) || !isWordChar(text[i])
+}
+
+// countDigits returns the length of the run of digits starting at position i.
+func countDigits(text []byte, i int) int {
+	n := 0
+	for i+n < len(text) && isDigit(text[i+n]) {
+		n++
+	}
+	return n
+}
+
+// scanComment matches `#(.+)?`: everything up to the end of the line.
+func scanComment(text []byte) int {
+	n := 1
+	for n < len(text) && text[n] != '\n' && !(text[n] == '\r' && n+1 < len(text) && text[n+1] == '\n') {
+		n++
+	}
+	return n
+}
+
+// scanQuotedString matches `"(.*?)"`: a quoted string that does not span lines.
+func scanQuotedString(text []byte) int {
+	for n := 1; n < len(text); n++ {
+		switch text[n] {
+		case '"':
+			return n + 1
+		case '\n':
+			return 0
+		}
+	}
+	return 0
+}
+
+// scanBool matches `(yes|no)\b`.
+func scanBool(text []byte) int {
+	for _, word := range [...]string{"yes", "no"} {
+		n := len(word)
+		if len(text) >= n && string(text[:n]) == word && isWordBoundary(text, n) {
+			return n
+		}
+	}
+	return 0
+}
+
+// scanDate matches `-?\d+\.\d{1,2}\.(\d{1,2})?`.
+func scanDate(text []byte) int {
+	i := 0
+	if i < len(text) && text[i] == '-' {
+		i++
+	}
+
+	year := countDigits(text, i)
+	if year == 0 {
+		return 0
+	}
+	i += year
+
+	if i >= len(text) || text[i] != '.' {
+		return 0
+	}
+	i++
+
+	month := countDigits(text, i)
+	if month == 0 || month > 2 {
+		return 0
+	}
+	i += month
+
+	if i >= len(text) || text[i] != '.' {
+		return 0
+	}
+	i++
+
+	return i + min(countDigits(text, i), 2)
+}
+
+// scanNumber matches `-?\d+([.,]\d+)?\b`.
+func scanNumber(text []byte) int {
+	i := 0
+	if i < len(text) && text[i] == '-' {
+		i++
+	}
+
+	digits := countDigits(text, i)
+	if digits == 0 {
+		return 0
+	}
+	i += digits
+
+	// A shorter run of digits is always followed by another digit, so \b can only hold
+	// after the full run, with or without the fractional part.
+	if i+1 < len(text) && (text[i] == '.' || text[i] == ',') {
+		if fraction := countDigits(text, i+1); fraction > 0 && isWordBoundary(text, i+1+fraction) {
+			return i + 1 + fraction
+		}
+	}
+
+	if isWordBoundary(text, i) {
+		return i
+	}
+	return 0
+}
+
+// scanWord matches `@?(?:[\w-]+:)?[\w.-]+`.
+func scanWord(text []byte) int {
+	i := 0
+	if i < len(text) && text[i] == '@' {
+		i++
+	}
+
+	// Optional `[\w-]+:` prefix, such as in scope:character
+	prefix := 0
+	for i+prefix < len(text) && (isWordChar(text[i+prefix]) || text[i+prefix] == '-') {
+		prefix++
+	}
+	if prefix > 0 && i+prefix+1 < len(text) && text[i+prefix] == ':' && isWordTail(text[i+prefix+1]) {
+		i += prefix + 1
+	}
+
+	n := 0
+	for i+n < len(text) && isWordTail(text[i+n]) {
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+
+	return i + n
+}
+
+// isWordTail reports whether c matches [\w.-].
+func isWordTail(c byte) bool {
+	return isWordChar(c) || c == '.' || c == '-'
+}
@@ -0,0 +1,216 @@
+package lexer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+)
+
+const fixturesDir = "../../../data"
+
+// regexMatchToken tries every pattern in tokens.TokenCheckOrder, the way the lexer used to.
+func regexMatchToken(tpm *TokenPatternMatcher, text []byte) (tokens.TokenType, int) {
+	for _, tokenType := range tokens.TokenCheckOrder {
+		if match := tpm.MatchToken(tokenType, text); match != nil {
+			return tokenType, len(match)
+		}
+	}
+	return 0, 0
+}
+
+func loadFixtures(tb testing.TB) map[string][]byte {
+	tb.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(fixturesDir, "*.txt"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	fixtures := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		fixtures[filepath.Base(path)] = content
+	}
+	return fixtures
+}
+
+func TestMatchToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantType tokens.TokenType
+		wantLen  int
+	}{
+		{"comment", "# comment\nkey", tokens.COMMENT, 9},
+		{"comment at EOF", "#", tokens.COMMENT, 1},
+		{"word", "key = value", tokens.WORD, 3},
+		{"scoped word", "scope:character = value", tokens.WORD, 15},
+		{"double scope", "a:b:c", tokens.WORD, 3},
+		{"scope without tail", "scope: x", tokens.WORD, 5},
+		{"variable", "@pos_compat_high = 30", tokens.WORD, 16},
+		{"lone at sign", "@ x", 0, 0},
+		{"dotted word", "stewardship_domain_special.1424.a", tokens.WORD, 33},
+		{"quoted string", `"DLC1" x`, tokens.QUOTED_STRING, 6},
+		{"unterminated string", "\"DLC1\nx\"", 0, 0},
+		{"integer", "123 x", tokens.NUMBER, 3},
+		{"float", "-0.25\n", tokens.NUMBER, 5},
+		{"comma float", "1,5 ", tokens.NUMBER, 3},
+		{"number then dot", "1.5x", tokens.NUMBER, 1},
+		{"number prefix of word", "107500_king_sancho", tokens.WORD, 18},
+		{"bool", "yes}", tokens.BOOL, 3},
+		{"bool prefix of word", "yes_man", tokens.WORD, 7},
+		{"no", "no", tokens.BOOL, 2},
+		{"date", "205.1.1 = {", tokens.DATE, 7},
+		{"date without day", "776.1. = {", tokens.DATE, 6},
+		{"date with long day", "205.1.123", tokens.DATE, 8},
+		{"not a date", "1.123.4", tokens.NUMBER, 5},
+		{"equals", "= x", tokens.EQUALS, 1},
+		{"double equals", "== x", tokens.EQUALS, 2},
+		{"question equals", "?= x", tokens.QUESTION_EQUALS, 2},
+		{"lone question mark", "? x", 0, 0},
+		{"comparison", ">= 10", tokens.COMPARISON, 2},
+		{"start", "{", tokens.START, 1},
+		{"end", "}", tokens.END, 1},
+		{"carriage return", "\r", tokens.WHITESPACE, 1},
+		{"unknown", "!= x", 0, 0},
+		{"non-ASCII", "имя = x", 0, 0},
+	}
+
+	tpm := NewTokenPatternMatcher()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotLen := matchToken([]byte(tt.text))
+			if gotLen != tt.wantLen || (gotLen > 0 && gotType != tt.wantType) {
+				t.Errorf("matchToken(%q) = %v, %d, want %v, %d", tt.text, gotType, gotLen, tt.wantType, tt.wantLen)
+			}
+
+			// The regular expressions remain the reference definition
+			refType, refLen := regexMatchToken(tpm, []byte(tt.text))
+			if gotLen != refLen || (gotLen > 0 && gotType != refType) {
+				t.Errorf("matchToken(%q) = %v, %d, regexes give %v, %d", tt.text, gotType, gotLen, refType, refLen)
+			}
+		})
+	}
+}
+
+func TestMatchToken_CRLF(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantType tokens.TokenType
+		wantLen  int
+	}{
+		{"CRLF", "\r\nkey", tokens.NEXTLINE, 2},
+		{"lone CR", "\rkey", tokens.WHITESPACE, 1},
+		{"comment before CRLF", "# comment\r\nkey", tokens.COMMENT, 9},
+		{"comment with CR", "# a\rb\n", tokens.COMMENT, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotLen := matchToken([]byte(tt.text))
+			if gotType != tt.wantType || gotLen != tt.wantLen {
+				t.Errorf("matchToken(%q) = %v, %d, want %v, %d", tt.text, gotType, gotLen, tt.wantType, tt.wantLen)
+			}
+		})
+	}
+}
+
+// TestMatchToken_Fixtures checks that the scanner agrees with the regular expressions
+// at every byte offset of the fixture files.
+func TestMatchToken_Fixtures(t *testing.T) {
+	tpm := NewTokenPatternMatcher()
+
+	for name, text := range loadFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			for i := range text {
+				gotType, gotLen := matchToken(text[i:])
+				refType, refLen := regexMatchToken(tpm, text[i:])
+				if gotLen != refLen || (gotLen > 0 && gotType != refType) {
+					t.Fatalf("offset %d (%q): matchToken = %v, %d, regexes give %v, %d",
+						i, text[i:min(i+20, len(text))], gotType, gotLen, refType, refLen)
+				}
+			}
+		})
+	}
+}
+
+// syntheticFile builds a large script resembling a history file.
+func syntheticFile(characters int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < characters; i++ {
+		buf.WriteString(strings.ReplaceAll(`70027 = {
+	name = "Teresa" # Teresa Ansúrez
+	female = yes
+	dynasty = 405
+	martial = -5
+	trait = education_learning_2
+	father = scope:character.father
+	941.1.1 = {
+		birth = yes
+	}
+	966.11.15 = {
+		add_gold >= 0.25
+		trait ?= devoted
+	}
+}
+`, "70027", "7"+strings.Repeat("0", i%5)))
+	}
+	return buf.Bytes()
+}
+
+func benchmarkMatcher(b *testing.B, text []byte, match func([]byte) (tokens.TokenType, int)) {
+	b.SetBytes(int64(len(text)))
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for cursor := 0; cursor < len(text); {
+			_, length := match(text[cursor:])
+			cursor += max(length, 1)
+		}
+	}
+}
+
+func BenchmarkMatchToken(b *testing.B) {
+	tpm := NewTokenPatternMatcher()
+	regexMatch := func(text []byte) (tokens.TokenType, int) {
+		return regexMatchToken(tpm, text)
+	}
+
+	inputs := loadFixtures(b)
+	inputs["synthetic"] = syntheticFile(5000)
+
+	for name, text := range inputs {
+		b.Run(name+"/scanner", func(b *testing.B) {
+			benchmarkMatcher(b, text, matchToken)
+		})
+		b.Run(name+"/regexp", func(b *testing.B) {
+			benchmarkMatcher(b, text, regexMatch)
+		})
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	text := syntheticFile(5000)
+	entry, err := files.OpenFileEntry(nil, fstest.MapFS{"synthetic.txt": {Data: text}}, "synthetic.txt", files.Mod)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(text)))
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		Scan(entry, text)
+	}
+}
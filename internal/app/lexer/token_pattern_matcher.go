@@ -1,14 +1,15 @@
 package lexer
 
 import (
-	"fmt"
 	"log"
 	"regexp"
 
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 )
 
-// TokenPatternMatcher is a structure for working with token regular expressions
+// TokenPatternMatcher is a structure for working with token regular expressions.
+// The lexer itself uses the hand-written scanner in scanner.go; the regular expressions
+// remain the reference definition of each token type.
 type TokenPatternMatcher struct {
 	compiledRegexMap map[tokens.TokenType]*regexp.Regexp
 }
@@ -33,11 +34,11 @@ func (tpm *TokenPatternMatcher) compileRegexes() {
 	}
 }
 
-// MatchToken finds the first match for the given token type and text
+// MatchToken finds the first match for the given token type and text.
+// It returns nil if there is none, or if the token type has no regular expression, such as UNKNOWN.
 func (tpm *TokenPatternMatcher) MatchToken(tokenType tokens.TokenType, text []byte) []byte {
 	regex, exists := tpm.compiledRegexMap[tokenType]
 	if !exists {
-		fmt.Printf("No regex found for TokenType %s\n", tokenType)
 		return nil
 	}
 	return regex.Find(text)
@@ -155,6 +155,12 @@ func TestTokenPatternMatcher_MatchToken(t *testing.T) {
 			text:      []byte(">= 10"),
 			want:      []byte(">="),
 		},
+		{
+			name:      "No regex for UNKNOWN",
+			tokenType: tokens.UNKNOWN,
+			text:      []byte("№ 5"),
+			want:      nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,165 @@
+package tokens
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// DefaultTabWidth is the tab width used for visual columns when none is configured
+const DefaultTabWidth = 4
+
+// LineIndex maps byte offsets of a text to lines and columns.
+// Both LF and CRLF line endings are recognized.
+type LineIndex struct {
+	text []byte
+	// byte offset of the start of each line
+	starts []int
+}
+
+// NewLineIndex indexes the lines of text
+func NewLineIndex(text []byte) *LineIndex {
+	starts := []int{0}
+	for i, c := range text {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	return &LineIndex{text: text, starts: starts}
+}
+
+// LineCount returns the number of lines in the text
+func (li *LineIndex) LineCount() int {
+	return len(li.starts)
+}
+
+// Line returns the text of the 1-based line n without its line ending,
+// or nil if there is no such line
+func (li *LineIndex) Line(n int) []byte {
+	if n < 1 || n > len(li.starts) {
+		return nil
+	}
+
+	start := li.starts[n-1]
+	end := len(li.text)
+	if n < len(li.starts) {
+		end = li.starts[n] - 1
+		if end > start && li.text[end-1] == '\r' {
+			end--
+		}
+	}
+
+	return li.text[start:end]
+}
+
+// Offset returns the byte offset of a 1-based line and character column.
+// Lines and columns past the end of the text or of the line are clamped to it.
+func (li *LineIndex) Offset(line int, column int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(li.starts) {
+		return len(li.text)
+	}
+	return li.starts[line-1] + ColumnOffset(li.Line(line), column)
+}
+
+// Position returns the 1-based line of the offset and the offset of that line's start.
+// Offsets past the end of the text are clamped to it.
+func (li *LineIndex) Position(offset int) (line int, lineStart int) {
+	offset = max(0, min(offset, len(li.text)))
+	line = sort.Search(len(li.starts), func(i int) bool { return li.starts[i] > offset })
+	return line, li.starts[line-1]
+}
+
+// RuneColumn returns the 1-based column of the offset, counted in characters
+func (li *LineIndex) RuneColumn(offset int) int {
+	prefix := li.linePrefix(offset)
+	return utf8.RuneCount(prefix) + 1
+}
+
+// UTF16Column returns the 1-based column of the offset, counted in UTF-16 code units
+// as editors and the Language Server Protocol do
+func (li *LineIndex) UTF16Column(offset int) int {
+	return UTF16Width(li.linePrefix(offset)) + 1
+}
+
+// VisualColumn returns the 1-based column of the offset as displayed in a terminal,
+// with tabs advancing to the next multiple of tabWidth
+func (li *LineIndex) VisualColumn(offset int, tabWidth int) int {
+	return VisualWidth(li.linePrefix(offset), tabWidth) + 1
+}
+
+// linePrefix returns the text between the start of the offset's line and the offset
+func (li *LineIndex) linePrefix(offset int) []byte {
+	offset = max(0, min(offset, len(li.text)))
+	_, start := li.Position(offset)
+	return li.text[start:offset]
+}
+
+// UTF16Width returns the number of UTF-16 code units needed to encode text
+func UTF16Width(text []byte) int {
+	width := 0
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		text = text[size:]
+		if r >= 0x10000 {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// VisualWidth returns the number of terminal cells text takes when it starts at the beginning of a line.
+// Tabs advance to the next multiple of tabWidth, wide East Asian characters take two cells.
+func VisualWidth(text []byte, tabWidth int) int {
+	if tabWidth <= 0 {
+		tabWidth = DefaultTabWidth
+	}
+
+	width := 0
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		text = text[size:]
+		switch {
+		case r == '\t':
+			width += tabWidth - width%tabWidth
+		case isWide(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// isWide reports whether r is displayed in two terminal cells.
+// It covers the common East Asian wide ranges rather than the full Unicode tables.
+func isWide(r rune) bool {
+	return (r >= 0x1100 && r <= 0x115F) || // Hangul Jamo
+		(r >= 0x2E80 && r <= 0x303E) || // CJK Radicals, Kangxi, CJK Symbols and Punctuation
+		(r >= 0x3041 && r <= 0x33FF) || // Hiragana, Katakana, CJK compatibility
+		(r >= 0x3400 && r <= 0x4DBF) || // CJK Extension A
+		(r >= 0x4E00 && r <= 0x9FFF) || // CJK Unified Ideographs
+		(r >= 0xA000 && r <= 0xA4CF) || // Yi
+		(r >= 0xAC00 && r <= 0xD7A3) || // Hangul Syllables
+		(r >= 0xF900 && r <= 0xFAFF) || // CJK Compatibility Ideographs
+		(r >= 0xFE30 && r <= 0xFE4F) || // CJK Compatibility Forms
+		(r >= 0xFF00 && r <= 0xFF60) || // Fullwidth Forms
+		(r >= 0xFFE0 && r <= 0xFFE6) ||
+		(r >= 0x1F300 && r <= 0x1F64F) || // Emoji
+		(r >= 0x20000 && r <= 0x3FFFD) // CJK Extensions B and later
+}
+
+// ColumnOffset returns the byte offset within line of the 1-based character column,
+// clamped to the length of the line
+func ColumnOffset(line []byte, column int) int {
+	offset := 0
+	for i := 1; i < column && offset < len(line); i++ {
+		_, size := utf8.DecodeRune(line[offset:])
+		offset += size
+	}
+	return offset
+}
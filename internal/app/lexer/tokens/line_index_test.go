@@ -0,0 +1,113 @@
+package tokens
+
+import "testing"
+
+func TestLineIndex_Columns(t *testing.T) {
+	text := []byte("key = value\r\n\tимя = \"名前\" # 😀 x\nlast")
+	li := NewLineIndex(text)
+
+	if li.LineCount() != 3 {
+		t.Fatalf("LineCount() = %d, want 3", li.LineCount())
+	}
+	if got := string(li.Line(1)); got != "key = value" {
+		t.Errorf("Line(1) = %q, want CRLF to be stripped", got)
+	}
+	if got := string(li.Line(3)); got != "last" {
+		t.Errorf("Line(3) = %q", got)
+	}
+	if li.Line(4) != nil {
+		t.Errorf("Line(4) should be nil")
+	}
+
+	secondLine := 13 // offset of the tab
+
+	tests := []struct {
+		name        string
+		offset      int
+		wantLine    int
+		wantRune    int
+		wantUTF16   int
+		wantVisual4 int
+		wantVisual8 int
+	}{
+		{"start of file", 0, 1, 1, 1, 1, 1},
+		{"value", 6, 1, 7, 7, 7, 7},
+		{"tab", secondLine, 2, 1, 1, 1, 1},
+		{"after tab", secondLine + 1, 2, 2, 2, 5, 9},
+		{"after cyrillic", secondLine + 1 + len("имя"), 2, 5, 5, 8, 12},
+		{"after CJK string", secondLine + 1 + len("имя = \"名前\""), 2, 12, 12, 17, 21},
+		{"after emoji", secondLine + 1 + len("имя = \"名前\" # 😀"), 2, 16, 17, 22, 26},
+		{"last line", len(text) - 1, 3, 4, 4, 4, 4},
+		{"past the end", len(text) + 10, 3, 5, 5, 5, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if line, _ := li.Position(tt.offset); line != tt.wantLine {
+				t.Errorf("Position(%d) line = %d, want %d", tt.offset, line, tt.wantLine)
+			}
+			if got := li.RuneColumn(tt.offset); got != tt.wantRune {
+				t.Errorf("RuneColumn(%d) = %d, want %d", tt.offset, got, tt.wantRune)
+			}
+			if got := li.UTF16Column(tt.offset); got != tt.wantUTF16 {
+				t.Errorf("UTF16Column(%d) = %d, want %d", tt.offset, got, tt.wantUTF16)
+			}
+			if got := li.VisualColumn(tt.offset, 4); got != tt.wantVisual4 {
+				t.Errorf("VisualColumn(%d, 4) = %d, want %d", tt.offset, got, tt.wantVisual4)
+			}
+			if got := li.VisualColumn(tt.offset, 8); got != tt.wantVisual8 {
+				t.Errorf("VisualColumn(%d, 8) = %d, want %d", tt.offset, got, tt.wantVisual8)
+			}
+		})
+	}
+}
+
+func TestColumnOffset(t *testing.T) {
+	line := []byte("\tимя = x")
+
+	tests := []struct {
+		column int
+		want   int
+	}{
+		{1, 0},
+		{2, 1},
+		{3, 3},
+		{5, 7},
+		{100, len(line)},
+	}
+
+	for _, tt := range tests {
+		if got := ColumnOffset(line, tt.column); got != tt.want {
+			t.Errorf("ColumnOffset(%q, %d) = %d, want %d", line, tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestLineIndex_Offset(t *testing.T) {
+	text := []byte("key = value\r\n\tимя = yes\nlast")
+	li := NewLineIndex(text)
+
+	tests := []struct {
+		name   string
+		line   int
+		column int
+		want   int
+	}{
+		{"start of file", 1, 1, 0},
+		{"value", 1, 7, 6},
+		{"start of second line", 2, 1, 13},
+		{"after cyrillic", 2, 5, 13 + 1 + len("имя")},
+		{"past the end of the line", 2, 100, 13 + len("\tимя = yes")},
+		{"last line", 3, 2, len(text) - 3},
+		{"before the first line", 0, 1, 0},
+		{"past the last line", 4, 1, len(text)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := li.Offset(tt.line, tt.column); got != tt.want {
+				t.Errorf("Offset(%d, %d) = %d, want %d", tt.line, tt.column, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,10 +11,14 @@ import (
 
 // Loc представляет позицию сущности в файле
 type Loc struct {
-	idx    files.PathTableIndex `json:"-"`
-	Line   uint32               `json:"line"`
-	Column uint16               `json:"column"`
-	kind   files.FileKind       `json:"-"`
+	idx  files.PathTableIndex `json:"-"`
+	Line uint32               `json:"line"`
+	// Column считается в символах (рунах), табуляция занимает одну колонку.
+	// Колонки в UTF-16 и визуальные колонки вычисляются через LineIndex
+	Column uint32 `json:"column"`
+	// Offset — смещение в байтах от начала файла
+	Offset uint32         `json:"offset"`
+	kind   files.FileKind `json:"-"`
 }
 
 // ForFile создает новый Loc для файла
@@ -30,7 +34,7 @@ type Loc struct {
 
 // Filename возвращает имя файла из Loc
 func (loc *Loc) Filename() (string, error) {
-	path, err := files.PATHTABLE.LookupFullpath(loc.idx)
+	path, err := loc.idx.Fullpath()
 	if err != nil {
 		return "", err
 	}
@@ -40,7 +44,7 @@ func (loc *Loc) Filename() (string, error) {
 
 // Pathname возвращает относительный путь из Loc
 func (loc *Loc) Pathname() (string, error) {
-	path, err := files.PATHTABLE.LookupFullpath(loc.idx)
+	path, err := loc.idx.Fullpath()
 	if err != nil {
 		return "", err
 	}
@@ -49,7 +53,7 @@ func (loc *Loc) Pathname() (string, error) {
 
 // Fullpath возвращает полный путь из Loc
 func (loc *Loc) Fullpath() (string, error) {
-	fullpath, err := files.PATHTABLE.LookupFullpath(loc.idx)
+	fullpath, err := loc.idx.Fullpath()
 	if err != nil {
 		return "", err
 	}
@@ -77,6 +81,20 @@ func LocFromFileEntry(entry *files.FileEntry) *Loc {
 	}
 }
 
+// Kind returns whether the file of the Loc belongs to the game or to the mod
+func (loc *Loc) Kind() files.FileKind {
+	return loc.kind
+}
+
+// Source returns the game or the mod that the file of the Loc comes from
+func (loc *Loc) Source() files.Source {
+	source, err := loc.idx.Source()
+	if err != nil {
+		return files.Source{}
+	}
+	return source
+}
+
 func (loc *Loc) GetIdx() files.PathTableIndex {
 	return loc.idx
 }
@@ -0,0 +1,17 @@
+package tokens
+
+// Span is a half-open range [Start, End) of byte offsets in a file
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Len returns the number of bytes in the span
+func (s Span) Len() int {
+	return s.End - s.Start
+}
+
+// IsEmpty reports whether the span covers no bytes, e.g. for tokens that were not read from a file
+func (s Span) IsEmpty() bool {
+	return s.End <= s.Start
+}
@@ -9,6 +9,8 @@ type Token struct {
 	Value string    `json:"value"`
 	Type  TokenType `json:"type"`
 	Loc   Loc       `json:"-"`
+	// Span is the range of bytes the token was read from
+	Span Span `json:"-"`
 }
 
 func New(value string, tokenType TokenType, loc Loc) *Token {
@@ -3,6 +3,8 @@ package tokens
 type TokenStream struct {
 	Tokens   []*Token
 	Position int
+	// Comments are all the comments of the file, even the ones the lexer leaves out of Tokens
+	Comments []*Token
 }
 
 func NewTokenStream() *TokenStream {
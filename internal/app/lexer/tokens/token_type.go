@@ -17,6 +17,9 @@ const (
 	TAB
 	COMPARISON
 	DATE
+	// UNKNOWN is a character that doesn't start any token.
+	// It is only emitted when the lexer keeps trivia, so that no byte of the input is lost.
+	UNKNOWN
 )
 
 var TokenTypeRegexMap = map[TokenType]string{
@@ -84,6 +87,8 @@ func (tt TokenType) String() string {
 		return "COMPARISON"
 	case DATE:
 		return "DATE"
+	case UNKNOWN:
+		return "UNKNOWN"
 	default:
 		return "UNKNOWN"
 	}
@@ -4,4 +4,6 @@ type AST struct {
 	Filename string     `json:"filename"`
 	Fullpath string     `json:"fullpath"`
 	Block    *FileBlock `json:"data"`
+	// Directives are the comments of the file that give instructions to gock3
+	Directives []*Directive `json:"-"`
 }
@@ -1,6 +1,8 @@
 package ast
 
 import (
+	"encoding/json"
+
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 )
 
@@ -21,6 +23,14 @@ type FileBlock = FieldBlock
 type FieldBlock struct {
 	Values []*Field   `json:"fields"`
 	Loc    tokens.Loc `json:"-"`
+	// Span covers the block from its opening to its closing brace, it is empty for files and unclosed blocks
+	Span tokens.Span `json:"-"`
+
+	// Comments that are not attached to any field, such as the ones before the closing brace
+	Comments []*tokens.Token `json:"comments,omitempty"`
+
+	// Errors are the parts of the block that the parser skipped
+	Errors []*ErrorNode `json:"errors,omitempty"`
 }
 
 func (fb *FieldBlock) IsBlock() {}
@@ -121,11 +131,55 @@ func (fb *FieldBlock) GetTokenBlock(key string) *TokenBlock {
 // Token Block is a block with a list of tokens
 type TokenBlock struct {
 	Values []*tokens.Token `json:"tokens"`
+	// Span covers the block from its opening to its closing brace, it is empty for unclosed blocks
+	Span tokens.Span `json:"-"`
+
+	// Comments found between the tokens of the block
+	Comments []*tokens.Token `json:"comments,omitempty"`
+
+	// Errors are the parts of the block that the parser skipped
+	Errors []*ErrorNode `json:"errors,omitempty"`
 }
 
 func (tb *TokenBlock) IsBlock() {}
 func (tb *TokenBlock) IsBV()    {}
 
+// Mixed Block is a block with both bare values and fields, such as `{ a b key = value }`.
+// It embeds the FieldBlock of its fields, so the lookup helpers work on it as on any field block,
+// while Items keeps the fields and the bare values in source order.
+type MixedBlock struct {
+	FieldBlock `json:"-"`
+	Items      []*BlockItem `json:"items"`
+}
+
+func (mb *MixedBlock) IsBlock() {}
+func (mb *MixedBlock) IsBV()    {}
+
+// BareValues returns the values of the block that are not fields, in source order
+func (mb *MixedBlock) BareValues() []BV {
+	res := make([]BV, 0, len(mb.Items)-len(mb.Values))
+	for _, item := range mb.Items {
+		if item.Field == nil {
+			res = append(res, item.Value)
+		}
+	}
+	return res
+}
+
+func (mb *MixedBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Items    []*BlockItem    `json:"items"`
+		Comments []*tokens.Token `json:"comments,omitempty"`
+		Errors   []*ErrorNode    `json:"errors,omitempty"`
+	}{mb.Items, mb.Comments, mb.Errors})
+}
+
+// BlockItem is an element of a mixed block: either a field or a bare value, which is a token or a block
+type BlockItem struct {
+	Field *Field `json:"field,omitempty"`
+	Value BV     `json:"value,omitempty"`
+}
+
 type EmptyValue struct {
 	Loc tokens.Loc `json:"-"`
 }
@@ -0,0 +1,14 @@
+package ast
+
+import "github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+
+// Directive is a comment that gives an instruction to gock3, such as `# gock3:ignore trait/out-of-range`
+type Directive struct {
+	Comment *tokens.Token
+	// Name is the word right after gock3:, such as ignore
+	Name string
+	// Args are the words after the name, commas separate them as well as spaces
+	Args []string
+	// OwnLine is whether the comment is alone on its line, rather than after a field
+	OwnLine bool
+}
@@ -0,0 +1,22 @@
+package ast
+
+import "github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+
+// ErrorNode is syntax that the parser skipped while recovering from an error.
+// The diagnostics are reported separately, the node keeps what was skipped and where,
+// so that later passes know which parts of the file are missing from the tree.
+type ErrorNode struct {
+	Tokens []*tokens.Token `json:"tokens"`
+	Span   tokens.Span     `json:"span"`
+	Loc    tokens.Loc      `json:"-"`
+}
+
+// NewErrorNode creates an error node covering the skipped tokens
+func NewErrorNode(skipped []*tokens.Token) *ErrorNode {
+	first, last := skipped[0], skipped[len(skipped)-1]
+	return &ErrorNode{
+		Tokens: skipped,
+		Span:   tokens.Span{Start: first.Span.Start, End: last.Span.End},
+		Loc:    first.Loc,
+	}
+}
@@ -1,9 +1,62 @@
 package ast
 
-import "github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+import (
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/date"
+)
 
 type Field struct {
 	Key      *tokens.Token `json:"key"`
 	Operator *tokens.Token `json:"operator"`
 	Value    BV            `json:"value"`
+
+	// Comments are only collected when the lexer keeps them, see lexer.Options.
+	// LeadingComments are the comments on the lines right above the field,
+	// TrailingComments are the ones on the same line as its key, opening brace or end,
+	// and the ones between its operator and a value on a later line.
+	LeadingComments  []*tokens.Token `json:"leading_comments,omitempty"`
+	TrailingComments []*tokens.Token `json:"trailing_comments,omitempty"`
+}
+
+// DateKey returns the key as a date, for dated entries such as `1066.9.15 = { ... }`.
+// It reports false if the key is not a date, or not one that exists in the calendar.
+func (f *Field) DateKey() (date.Date, bool) {
+	if f.Key == nil || f.Key.Type != tokens.DATE {
+		return date.Date{}, false
+	}
+
+	d, err := date.Parse(f.Key.Value)
+	if err != nil {
+		return date.Date{}, false
+	}
+	return d, true
+}
+
+// Span returns the range of the field from its key to the end of its value.
+// It is empty if the end is unknown, as for a block that is not closed.
+func (f *Field) Span() tokens.Span {
+	if f.Key == nil || f.Key.Span.IsEmpty() {
+		return tokens.Span{}
+	}
+
+	var end tokens.Span
+	switch value := f.Value.(type) {
+	case *tokens.Token:
+		end = value.Span
+	case *FieldBlock:
+		end = value.Span
+	case *TokenBlock:
+		end = value.Span
+	case *MixedBlock:
+		end = value.Span
+	default:
+		if f.Operator != nil {
+			end = f.Operator.Span
+		}
+	}
+
+	if end.IsEmpty() {
+		return tokens.Span{}
+	}
+	return tokens.Span{Start: f.Key.Span.Start, End: end.End}
 }
@@ -7,46 +7,127 @@ import (
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
-// Block parses a block and returns the corresponding AST node.
+// Block parses a block and returns the corresponding AST node:
+// a FieldBlock if it only has fields, a TokenBlock if it only has bare values,
+// and a MixedBlock if it has both.
 func (p *Parser) Block() ast.Block {
-	p.Expect(tokens.START)
+	open := p.Expect(tokens.START)
 	loc := *p.loc
 
-	if p.currentToken.Type == tokens.END {
-		p.Expect(tokens.END)
-		return &ast.FieldBlock{Values: []*ast.Field{}, Loc: loc}
+	// Comments right after the opening brace belong to the field that owns the block
+	opening := p.takeTrailingComments()
+	if p.owner != nil {
+		p.owner.TrailingComments = append(p.owner.TrailingComments, opening...)
+	} else {
+		p.pending = append(opening, p.pending...)
 	}
 
-	var block ast.Block
+	items, comments := p.BlockItems()
+	block := newBlock(items, loc)
+
+	// Comments not claimed by any field, and the syntax skipped inside the block
+	comments = append(comments, p.takeComments()...)
+	errorNodes := p.takeErrorNodes()
+	switch b := block.(type) {
+	case *ast.FieldBlock:
+		b.Comments = append(b.Comments, comments...)
+		b.Errors = errorNodes
+	case *ast.TokenBlock:
+		b.Comments = append(b.Comments, comments...)
+		b.Errors = errorNodes
+	case *ast.MixedBlock:
+		b.Comments = append(b.Comments, comments...)
+		b.Errors = errorNodes
+	}
+
+	// Expect closing brace '}', pointing at the opening one if the file ends first
+	if p.currentToken == nil && open != nil {
+		p.AddError(report.FromLoc(*p.loc, rules.ParseUnclosedBlock, errUnclosedBlock).
+			WithRelated(report.TokenPointer(open), "block opened here"))
+		return block
+	}
+	if closing := p.Expect(tokens.END); closing != nil && open != nil {
+		span := tokens.Span{Start: open.Span.Start, End: closing.Span.End}
+		switch b := block.(type) {
+		case *ast.FieldBlock:
+			b.Span = span
+		case *ast.TokenBlock:
+			b.Span = span
+		case *ast.MixedBlock:
+			b.Span = span
+		}
+	}
+
+	return block
+}
+
+// BlockItems parses the fields and bare values of a block in source order, up to the closing brace.
+// The comments around bare values are returned separately, since no field owns them.
+func (p *Parser) BlockItems() ([]*ast.BlockItem, []*tokens.Token) {
+	items := make([]*ast.BlockItem, 0)
+	var comments []*tokens.Token
 
 	for p.currentToken != nil && p.currentToken.Type != tokens.END {
 		switch p.currentToken.Type {
 		case tokens.NEXTLINE:
 			p.skipTokens(tokens.NEXTLINE)
 			continue
-		case tokens.WORD, tokens.DATE, tokens.QUOTED_STRING, tokens.NUMBER:
+		case tokens.WORD, tokens.DATE, tokens.NUMBER, tokens.QUOTED_STRING, tokens.BOOL:
 			if p.isNextField() {
-				block = p.FieldBlock(loc)
-			} else {
-				block = p.TokenBlock()
+				if field := p.Field(); field != nil {
+					items = append(items, &ast.BlockItem{Field: field})
+				}
+				continue
+			}
+
+			comments = append(comments, p.takeComments()...)
+			if token := p.Literal(); token != nil {
+				items = append(items, &ast.BlockItem{Value: token})
 			}
+			comments = append(comments, p.takeTrailingComments()...)
+		case tokens.START:
+			// A nested block is a bare value, so the field that owns this block doesn't own its comments
+			comments = append(comments, p.takeComments()...)
+			owner := p.owner
+			p.owner = nil
+			items = append(items, &ast.BlockItem{Value: p.Block()})
+			p.owner = owner
+			comments = append(comments, p.takeTrailingComments()...)
 		default:
 			errorMsg := fmt.Sprintf(errBlockUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-			err := report.FromToken(p.currentToken, severity.Error, errorMsg)
+			err := report.FromToken(p.currentToken, rules.ParseUnexpectedToken, errorMsg)
 			p.AddError(err)
 			p.synchronize(BlockRecovery)
-			continue
 		}
-		break // Exit the loop after processing the block
 	}
 
-	// Expect closing brace '}'
-	p.Expect(tokens.END)
+	return items, comments
+}
 
-	return block
+// newBlock builds the block node that fits the kinds of items it has
+func newBlock(items []*ast.BlockItem, loc tokens.Loc) ast.Block {
+	fields := make([]*ast.Field, 0, len(items))
+	values := make([]*tokens.Token, 0, len(items))
+	for _, item := range items {
+		if item.Field != nil {
+			fields = append(fields, item.Field)
+		} else if token, ok := item.Value.(*tokens.Token); ok {
+			values = append(values, token)
+		}
+	}
+
+	switch len(items) {
+	case len(fields):
+		// Also an empty block, which is the same as an empty list of fields
+		return &ast.FieldBlock{Values: fields, Loc: loc}
+	case len(values):
+		return &ast.TokenBlock{Values: values}
+	default:
+		return &ast.MixedBlock{FieldBlock: ast.FieldBlock{Values: fields, Loc: loc}, Items: items}
+	}
 }
 
 func (p *Parser) skipTokens(types ...tokens.TokenType) {
@@ -67,55 +148,5 @@ func (p *Parser) skipTokens(types ...tokens.TokenType) {
 
 // isNextField determines if the next construct is likely a field.
 func (p *Parser) isNextField() bool {
-	return isKeyToken(p.currentToken.Type) && isOperatorToken(p.lookahead.Type)
-}
-
-// FieldBlock parses a block of fields and returns the corresponding AST node.
-func (p *Parser) FieldBlock(loc tokens.Loc) *ast.FieldBlock {
-	fields := p.FieldList(tokens.END)
-	return &ast.FieldBlock{Values: fields, Loc: loc}
-}
-
-// TokenBlock parses a block of tokens and returns the corresponding AST node.
-func (p *Parser) TokenBlock() *ast.TokenBlock {
-	tokensList := p.TokenList(tokens.END)
-	return &ast.TokenBlock{Values: tokensList}
-}
-
-// TokenList parses a list of tokens until a stop token is encountered.
-func (p *Parser) TokenList(stopLookahead ...tokens.TokenType) []*tokens.Token {
-	tokensList := make([]*tokens.Token, 0)
-
-	for p.currentToken != nil {
-		// Check for stop tokens to end the token list
-		if len(stopLookahead) > 0 && p.currentToken.Type == stopLookahead[0] {
-			break
-		}
-
-		switch p.currentToken.Type {
-		case tokens.NEXTLINE:
-			p.Expect(tokens.NEXTLINE)
-			continue
-		case tokens.NUMBER, tokens.QUOTED_STRING, tokens.WORD:
-			token := p.Literal()
-			if token != nil {
-				tokensList = append(tokensList, token)
-			}
-		default:
-			errMsg := fmt.Sprintf(errTokenListUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-			err := report.FromToken(p.currentToken, severity.Error, errMsg)
-			p.AddError(err)
-
-			recoveryPoint := RecoveryPoint{
-				TokenTypes: []tokens.TokenType{tokens.END, tokens.WORD, tokens.DATE},
-				Context:    "TokenList",
-			}
-
-			if _, recovered := p.synchronize(recoveryPoint); !recovered {
-				return tokensList // Stop parsing if recovery fails
-			}
-		}
-	}
-
-	return tokensList
+	return isKeyToken(p.currentToken.Type) && p.lookahead != nil && isOperatorToken(p.lookahead.Type)
 }
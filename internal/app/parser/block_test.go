@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/pkg/report"
+)
+
+// parseTextWithErrors is like parseText, but also returns the diagnostics.
+func parseTextWithErrors(t *testing.T, text string) (*ast.FileBlock, []*report.DiagnosticItem) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, lexerErrs := lexer.Scan(entry, []byte(text))
+	block, parserErrs := Parse(stream)
+	return block, append(lexerErrs, parserErrs...)
+}
+
+// describeItems returns the keys of fields and the values of bare tokens, with "{}" for nested blocks
+func describeItems(items []*ast.BlockItem) []string {
+	result := make([]string, len(items))
+	for i, item := range items {
+		switch value := item.Value.(type) {
+		case *tokens.Token:
+			result[i] = value.Value
+		case ast.Block:
+			result[i] = "{}"
+		default:
+			result[i] = item.Field.Key.Value + "="
+		}
+	}
+	return result
+}
+
+func TestBlock_Kinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		want  ast.Block
+		items []string
+	}{
+		{"fields", "a = { b = c d = e }", &ast.FieldBlock{}, nil},
+		{"empty", "a = { }", &ast.FieldBlock{}, nil},
+		{"values", "color = { 0.5 0.3 0.1 }", &ast.TokenBlock{}, nil},
+		{"values of all kinds", "a = { b 1 yes \"c\" 867.1.1 }", &ast.TokenBlock{}, nil},
+		{"values then fields", "a = { b c key = value }", &ast.MixedBlock{}, []string{"b", "c", "key="}},
+		{"fields then values", "a = { key = value\n\tb c\n}", &ast.MixedBlock{}, []string{"key=", "b", "c"}},
+		{"interleaved", "a = { b key = { x = y } c }", &ast.MixedBlock{}, []string{"b", "key=", "c"}},
+		{"nested blocks", "a = { { b = c } { d } }", &ast.MixedBlock{}, []string{"{}", "{}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, errs := parseTextWithErrors(t, tt.text)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected diagnostics: %v", errs)
+			}
+
+			value := file.GetField("a")
+			if value == nil {
+				value = file.GetField("color")
+			}
+			if reflect.TypeOf(value.Value) != reflect.TypeOf(tt.want) {
+				t.Fatalf("block type = %T, want %T", value.Value, tt.want)
+			}
+
+			if mixed, ok := value.Value.(*ast.MixedBlock); ok {
+				if got := describeItems(mixed.Items); !reflect.DeepEqual(got, tt.items) {
+					t.Errorf("items = %q, want %q", got, tt.items)
+				}
+			}
+		})
+	}
+}
+
+func TestMixedBlock_Helpers(t *testing.T) {
+	file, errs := parseTextWithErrors(t, "list = { a b key = value names = { x y } inner = { c = d } e }")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", errs)
+	}
+
+	mixed, ok := file.GetField("list").Value.(*ast.MixedBlock)
+	if !ok {
+		t.Fatalf("list is %T, want *ast.MixedBlock", file.GetField("list").Value)
+	}
+
+	if got := mixed.GetFieldValue("key"); got == nil || got.Value != "value" {
+		t.Errorf("GetFieldValue(key) = %v", got)
+	}
+	if got := mixed.GetFieldList("names"); len(got) != 2 {
+		t.Errorf("GetFieldList(names) = %v", got)
+	}
+	if got := mixed.GetFieldBlock("inner"); got == nil || got.GetFieldValue("c").Value != "d" {
+		t.Errorf("GetFieldBlock(inner) = %v", got)
+	}
+	if got := len(mixed.GetFields("key")); got != 1 {
+		t.Errorf("GetFields(key) returned %d fields", got)
+	}
+
+	var bare []string
+	for _, value := range mixed.BareValues() {
+		bare = append(bare, value.(*tokens.Token).Value)
+	}
+	if want := []string{"a", "b", "e"}; !reflect.DeepEqual(bare, want) {
+		t.Errorf("BareValues() = %q, want %q", bare, want)
+	}
+}
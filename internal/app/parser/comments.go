@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+)
+
+// Comments are trivia: the grammar never sees them. When the lexer keeps COMMENT tokens,
+// the parser takes them out of the stream and remembers which token they preceded.
+// Every time a token becomes current, its comments are moved to the pending list,
+// from which fields and blocks claim them as leading or trailing comments.
+
+// splitComments returns a copy of the token stream without COMMENT tokens,
+// together with the comments that precede each remaining token and the comments at the end of input.
+// Other trivia that the lexer keeps for the concrete syntax tree is dropped.
+func splitComments(stream *tokens.TokenStream) (*tokens.TokenStream, map[*tokens.Token][]*tokens.Token, []*tokens.Token) {
+	var comments map[*tokens.Token][]*tokens.Token
+	var run []*tokens.Token
+
+	filtered := tokens.NewTokenStream()
+	for _, token := range stream.Tokens[stream.Position:] {
+		switch token.Type {
+		case tokens.COMMENT:
+			run = append(run, token)
+			continue
+		case tokens.WHITESPACE, tokens.TAB, tokens.UNKNOWN:
+			continue
+		}
+
+		if len(run) > 0 {
+			if comments == nil {
+				comments = make(map[*tokens.Token][]*tokens.Token)
+			}
+			comments[token] = run
+			run = nil
+		}
+		filtered.Push(token)
+	}
+
+	return filtered, comments, run
+}
+
+// collectComments moves the comments preceding the current token to the pending list.
+func (p *Parser) collectComments() {
+	if p.currentToken == nil {
+		return
+	}
+
+	if comments, ok := p.comments[p.currentToken]; ok {
+		p.pending = append(p.pending, comments...)
+		delete(p.comments, p.currentToken)
+	}
+}
+
+// takeComments claims all pending comments.
+func (p *Parser) takeComments() []*tokens.Token {
+	comments := p.pending
+	p.pending = nil
+	return comments
+}
+
+// takeTrailingComments claims the pending comments on the same line as the last consumed token.
+func (p *Parser) takeTrailingComments() []*tokens.Token {
+	if p.previous == nil || len(p.pending) == 0 {
+		return nil
+	}
+
+	var trailing, rest []*tokens.Token
+	for _, comment := range p.pending {
+		if comment.Loc.Line == p.previous.Loc.Line {
+			trailing = append(trailing, comment)
+		} else {
+			rest = append(rest, comment)
+		}
+	}
+
+	p.pending = rest
+	return trailing
+}
+
+// dropField gives the comments claimed by a field that failed to parse back to the pending list.
+func (p *Parser) dropField(field *ast.Field) *ast.Field {
+	comments := append(field.LeadingComments, field.TrailingComments...)
+	p.pending = append(comments, p.pending...)
+	return nil
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+)
+
+const fixturesDir = "../../../data"
+
+// parseText parses text written to a temporary file, so that tokens get a valid location.
+func parseText(t *testing.T, text string, options lexer.Options) *ast.FileBlock {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, _ := lexer.ScanWithOptions(entry, []byte(text), options)
+	block, _ := Parse(stream)
+	return block
+}
+
+func commentValues(comments []*tokens.Token) []string {
+	values := make([]string, len(comments))
+	for i, comment := range comments {
+		values[i] = comment.Value
+	}
+	return values
+}
+
+func countComments(block ast.BV) int {
+	switch b := block.(type) {
+	case *ast.FieldBlock:
+		count := len(b.Comments)
+		for _, field := range b.Values {
+			count += len(field.LeadingComments) + len(field.TrailingComments) + countComments(field.Value)
+		}
+		return count
+	case *ast.TokenBlock:
+		return len(b.Comments)
+	case *ast.MixedBlock:
+		count := len(b.Comments)
+		for _, item := range b.Items {
+			if item.Field != nil {
+				count += len(item.Field.LeadingComments) + len(item.Field.TrailingComments) + countComments(item.Field.Value)
+			} else {
+				count += countComments(item.Value)
+			}
+		}
+		return count
+	default:
+		return 0
+	}
+}
+
+func TestParse_Comments(t *testing.T) {
+	text := `# file header
+
+# about namespace
+namespace = test # trailing
+
+option = { # Option title
+	name = a
+	# dangling
+}
+opposites = {
+	chaste
+	# something
+	craven
+}
+# end of file`
+
+	block := parseText(t, text, lexer.Options{KeepComments: true})
+
+	if len(block.Values) != 3 {
+		t.Fatalf("expected 3 fields, got %d", len(block.Values))
+	}
+
+	namespace := block.Values[0]
+	if got := commentValues(namespace.LeadingComments); len(got) != 2 || got[0] != "# file header" || got[1] != "# about namespace" {
+		t.Errorf("namespace leading comments = %q", got)
+	}
+	if got := commentValues(namespace.TrailingComments); len(got) != 1 || got[0] != "# trailing" {
+		t.Errorf("namespace trailing comments = %q", got)
+	}
+
+	option := block.Values[1]
+	if got := commentValues(option.TrailingComments); len(got) != 1 || got[0] != "# Option title" {
+		t.Errorf("option trailing comments = %q", got)
+	}
+	if got := commentValues(option.Value.(*ast.FieldBlock).Comments); len(got) != 1 || got[0] != "# dangling" {
+		t.Errorf("option block comments = %q", got)
+	}
+
+	opposites := block.Values[2].Value.(*ast.TokenBlock)
+	if len(opposites.Values) != 2 {
+		t.Errorf("expected comments to be ignored by the grammar, got tokens %v", opposites.Values)
+	}
+	if got := commentValues(opposites.Comments); len(got) != 1 || got[0] != "# something" {
+		t.Errorf("opposites block comments = %q", got)
+	}
+
+	if got := commentValues(block.Comments); len(got) != 1 || got[0] != "# end of file" {
+		t.Errorf("file comments = %q", got)
+	}
+}
+
+func TestParse_CommentsDisabled(t *testing.T) {
+	block := parseText(t, "# comment\nkey = value # trailing\n", lexer.Options{})
+
+	if count := countComments(block); count != 0 {
+		t.Errorf("expected no comments without KeepComments, got %d", count)
+	}
+}
+
+func TestParse_CommentsFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fixturesDir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream, _ := lexer.ScanWithOptions(entry, content, lexer.Options{KeepComments: true})
+
+			want := 0
+			for _, token := range stream.Tokens {
+				if token.Type == tokens.COMMENT {
+					want++
+				}
+			}
+
+			block, _ := Parse(stream)
+
+			// Every comment must end up somewhere in the AST
+			if got := countComments(block); got != want {
+				t.Errorf("attached %d comments, but the lexer found %d", got, want)
+			}
+		})
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
 // Expect verifies that the current token matches one of the expected types.
@@ -18,8 +18,7 @@ func (p *Parser) Expect(expectedTypes ...tokens.TokenType) *tokens.Token {
 
 	if token == nil {
 		errMsg := fmt.Sprintf(errUnexpectedEOF, formatTokenTypes(expectedTypes))
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
-		p.AddError(err)
+		p.AddError(report.FromLoc(*p.loc, rules.ParseUnexpectedEOF, errMsg))
 		return nil
 	}
 
@@ -37,7 +36,7 @@ func (p *Parser) Expect(expectedTypes ...tokens.TokenType) *tokens.Token {
 		token.Type,
 		formatTokenTypes(expectedTypes),
 	)
-	err := report.FromToken(token, severity.Error, errMsg)
+	err := report.FromToken(token, rules.ParseUnexpectedToken, errMsg)
 	p.AddError(err)
 
 	// Create a recovery point based on the expected types
@@ -65,7 +64,7 @@ func (p *Parser) unquoteExpect(expectedType tokens.TokenType) *tokens.Token {
 	unquotedValue, err := strconv.Unquote(token.Value)
 	if err != nil {
 		errMsg := fmt.Sprintf(errFailedUnquoteString, token.Value)
-		diag := report.FromToken(token, severity.Error, errMsg)
+		diag := report.FromToken(token, rules.ParseInvalidString, errMsg)
 		p.AddError(diag)
 		// Keep the original value if unquoting fails
 		return token
@@ -0,0 +1,165 @@
+// Package cst defines the concrete syntax tree of PDXScript.
+//
+// Unlike the AST, the concrete syntax tree keeps every token of the input, including whitespace,
+// newlines, comments and invalid syntax, so printing it reproduces the source byte for byte.
+// It is the basis for automated rewrites of script files.
+package cst
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+)
+
+type Kind uint8
+
+const (
+	// KindFile is the root of the tree
+	KindFile Kind = iota
+	// KindField is a key, an operator, a value, and the trivia between them
+	KindField
+	// KindBlock is a value or a list item enclosed in braces, including the braces
+	KindBlock
+	// KindError is syntax the parser couldn't make sense of, such as a stray closing brace
+	KindError
+	// KindToken is a leaf holding a single token, either significant or trivia
+	KindToken
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindFile:
+		return "File"
+	case KindField:
+		return "Field"
+	case KindBlock:
+		return "Block"
+	case KindError:
+		return "Error"
+	case KindToken:
+		return "Token"
+	default:
+		return "Unknown"
+	}
+}
+
+// Node is a node of the concrete syntax tree.
+// Token nodes are leaves, all other kinds of nodes only have children.
+type Node struct {
+	Kind     Kind
+	Token    *tokens.Token
+	Children []*Node
+}
+
+// Tree is the concrete syntax tree of a whole file
+type Tree struct {
+	// BOM is whether the file started with a UTF-8 byte order mark, which is not part of any token
+	BOM  bool
+	Root *Node
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// NewToken creates a leaf node
+func NewToken(token *tokens.Token) *Node {
+	return &Node{Kind: KindToken, Token: token}
+}
+
+// IsTrivia reports whether the node is a whitespace, newline or comment token
+func (n *Node) IsTrivia() bool {
+	return n.Kind == KindToken && IsTrivia(n.Token.Type)
+}
+
+// IsTrivia reports whether tokens of the type carry no meaning for the grammar
+func IsTrivia(tokenType tokens.TokenType) bool {
+	switch tokenType {
+	case tokens.WHITESPACE, tokens.TAB, tokens.NEXTLINE, tokens.COMMENT:
+		return true
+	default:
+		return false
+	}
+}
+
+// Tokens returns all tokens under the node in source order
+func (n *Node) Tokens() []*tokens.Token {
+	var result []*tokens.Token
+	n.walkTokens(func(token *tokens.Token) {
+		result = append(result, token)
+	})
+	return result
+}
+
+// SignificantTokens returns the tokens under the node that are not trivia
+func (n *Node) SignificantTokens() []*tokens.Token {
+	var result []*tokens.Token
+	n.walkTokens(func(token *tokens.Token) {
+		if !IsTrivia(token.Type) {
+			result = append(result, token)
+		}
+	})
+	return result
+}
+
+func (n *Node) walkTokens(visit func(token *tokens.Token)) {
+	if n.Kind == KindToken {
+		visit(n.Token)
+		return
+	}
+	for _, child := range n.Children {
+		child.walkTokens(visit)
+	}
+}
+
+// Span returns the range of bytes covered by the node
+func (n *Node) Span() tokens.Span {
+	all := n.Tokens()
+	if len(all) == 0 {
+		return tokens.Span{}
+	}
+	return tokens.Span{Start: all[0].Span.Start, End: all[len(all)-1].Span.End}
+}
+
+// WriteTo writes the source text of the node
+func (n *Node) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	var err error
+	n.walkTokens(func(token *tokens.Token) {
+		if err != nil {
+			return
+		}
+		var count int
+		count, err = io.WriteString(w, token.Value)
+		written += int64(count)
+	})
+	return written, err
+}
+
+// String returns the source text of the node
+func (n *Node) String() string {
+	var buf bytes.Buffer
+	n.WriteTo(&buf)
+	return buf.String()
+}
+
+// WriteTo writes the source text of the whole file
+func (t *Tree) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	if t.BOM {
+		count, err := w.Write(utf8BOM)
+		written += int64(count)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	count, err := t.Root.WriteTo(w)
+	return written + count, err
+}
+
+// Bytes returns the source text of the whole file
+func (t *Tree) Bytes() []byte {
+	var buf bytes.Buffer
+	t.WriteTo(&buf)
+	return buf.Bytes()
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/cst"
+)
+
+// ParseCST builds the lossless concrete syntax tree from a token stream
+// that was lexed with lexer.Options.KeepTrivia.
+// It never fails: syntax it can't make sense of becomes cst.KindError nodes,
+// while the diagnostics for it are reported by Parse.
+func ParseCST(stream *tokens.TokenStream) *cst.Node {
+	b := &cstBuilder{tokens: stream.Tokens[stream.Position:]}
+	return &cst.Node{Kind: cst.KindFile, Children: b.items(false)}
+}
+
+type cstBuilder struct {
+	tokens []*tokens.Token
+	pos    int
+}
+
+// significant returns the position of the first non-trivia token at or after from
+func (b *cstBuilder) significant(from int) int {
+	for from < len(b.tokens) && cst.IsTrivia(b.tokens[from].Type) {
+		from++
+	}
+	return from
+}
+
+// typeAt returns the type of the token at position i, and false if there is none
+func (b *cstBuilder) typeAt(i int) (tokens.TokenType, bool) {
+	if i >= len(b.tokens) {
+		return 0, false
+	}
+	return b.tokens[i].Type, true
+}
+
+// take consumes the tokens up to position end as leaves
+func (b *cstBuilder) take(end int) []*cst.Node {
+	var leaves []*cst.Node
+	for ; b.pos < end; b.pos++ {
+		leaves = append(leaves, cst.NewToken(b.tokens[b.pos]))
+	}
+	return leaves
+}
+
+// isFieldStart reports whether the token at position i is a key followed by an operator
+func (b *cstBuilder) isFieldStart(i int) bool {
+	keyType, ok := b.typeAt(i)
+	if !ok || !isCSTLiteral(keyType) {
+		return false
+	}
+	operatorType, ok := b.typeAt(b.significant(i + 1))
+	return ok && isOperatorToken(operatorType)
+}
+
+// items parses fields, values and trivia until the end of input, or the closing brace of a block
+func (b *cstBuilder) items(inBlock bool) []*cst.Node {
+	var items []*cst.Node
+
+	for b.pos < len(b.tokens) {
+		token := b.tokens[b.pos]
+
+		switch {
+		case cst.IsTrivia(token.Type):
+			items = append(items, b.take(b.pos+1)...)
+		case token.Type == tokens.END && inBlock:
+			return items
+		case token.Type == tokens.START:
+			items = append(items, b.block())
+		case b.isFieldStart(b.pos):
+			items = append(items, b.field())
+		case isCSTLiteral(token.Type):
+			items = append(items, b.take(b.pos+1)...)
+		default:
+			// Stray closing braces, operators without a key, and unknown characters
+			items = append(items, &cst.Node{Kind: cst.KindError, Children: b.take(b.pos + 1)})
+		}
+	}
+
+	return items
+}
+
+// field parses a key, an operator and, if there is one, a value
+func (b *cstBuilder) field() *cst.Node {
+	field := &cst.Node{Kind: cst.KindField}
+
+	// Key, and the operator with the trivia before it
+	field.Children = append(field.Children, b.take(b.significant(b.pos+1)+1)...)
+
+	// The value may be on a later line, but a key with an operator starts the next field instead
+	valuePos := b.significant(b.pos)
+	valueType, ok := b.typeAt(valuePos)
+	switch {
+	case !ok:
+	case valueType == tokens.START:
+		field.Children = append(field.Children, b.take(valuePos)...)
+		field.Children = append(field.Children, b.block())
+	case isCSTLiteral(valueType) && !b.isFieldStart(valuePos):
+		field.Children = append(field.Children, b.take(valuePos+1)...)
+	}
+
+	return field
+}
+
+// block parses the braces and everything between them. An unclosed block ends with the input.
+func (b *cstBuilder) block() *cst.Node {
+	block := &cst.Node{Kind: cst.KindBlock, Children: b.take(b.pos + 1)}
+	block.Children = append(block.Children, b.items(true)...)
+
+	if tokenType, ok := b.typeAt(b.pos); ok && tokenType == tokens.END {
+		block.Children = append(block.Children, b.take(b.pos+1)...)
+	}
+
+	return block
+}
+
+// isCSTLiteral reports whether a token of the type can be a key or a value
+func isCSTLiteral(tokenType tokens.TokenType) bool {
+	return isLiteralType(tokenType) || tokenType == tokens.DATE
+}
@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/parser/cst"
+	"github.com/unLomTrois/gock3/internal/app/utils"
+)
+
+// parseCSTText builds the concrete syntax tree of text written to a temporary file.
+func parseCSTText(t *testing.T, text string) *cst.Node {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, _ := lexer.ScanWithOptions(entry, []byte(text), lexer.Options{KeepTrivia: true})
+	return ParseCST(stream)
+}
+
+func kinds(nodes []*cst.Node) []cst.Kind {
+	result := make([]cst.Kind, 0, len(nodes))
+	for _, node := range nodes {
+		if !node.IsTrivia() {
+			result = append(result, node.Kind)
+		}
+	}
+	return result
+}
+
+func TestParseCST_RoundTripFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fixturesDir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content, bom := utils.SplitUTF8BOM(raw)
+			entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream, _ := lexer.ScanWithOptions(entry, content, lexer.Options{KeepTrivia: true})
+
+			tree := &cst.Tree{BOM: bom, Root: ParseCST(stream)}
+			if got := tree.Bytes(); string(got) != string(raw) {
+				t.Errorf("printing the tree doesn't reproduce the file: got %d bytes, want %d", len(got), len(raw))
+			}
+		})
+	}
+}
+
+func TestParseCST_RoundTripEdgeCases(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		kinds []cst.Kind
+	}{
+		{"empty", "", []cst.Kind{}},
+		{"CRLF", "a = b\r\nc = { d }\r\n", []cst.Kind{cst.KindField, cst.KindField}},
+		{"stray brace", "a = b\n}\nc = d", []cst.Kind{cst.KindField, cst.KindError, cst.KindField}},
+		{"stray operator", "= b", []cst.Kind{cst.KindError, cst.KindToken}},
+		{"unclosed block", "a = {\n\tb = c\n", []cst.Kind{cst.KindField}},
+		{"missing value", "a =\nb = c", []cst.Kind{cst.KindField, cst.KindField}},
+		{"value on next line", "a =\n{ b }", []cst.Kind{cst.KindField}},
+		{"unknown characters", "a = имя # комментарий\n", []cst.Kind{cst.KindField, cst.KindError, cst.KindError, cst.KindError}},
+		{"bare values", "{ 1 2 }\nyes", []cst.Kind{cst.KindBlock, cst.KindToken}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parseCSTText(t, tt.text)
+
+			if got := root.String(); got != tt.text {
+				t.Errorf("String() = %q, want %q", got, tt.text)
+			}
+
+			got := kinds(root.Children)
+			if len(got) != len(tt.kinds) {
+				t.Fatalf("top-level kinds = %v, want %v", got, tt.kinds)
+			}
+			for i := range got {
+				if got[i] != tt.kinds[i] {
+					t.Errorf("top-level kinds = %v, want %v", got, tt.kinds)
+					break
+				}
+			}
+		})
+	}
+}
+
+// TestParse_TriviaStream checks that the AST doesn't depend on whether the lexer kept trivia.
+func TestParse_TriviaStream(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fixturesDir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := utils.SplitUTF8BOM(raw)
+			entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			plain, _ := lexer.ScanWithOptions(entry, content, lexer.Options{KeepComments: true})
+			trivia, _ := lexer.ScanWithOptions(entry, content, lexer.Options{KeepTrivia: true})
+
+			want, _ := Parse(plain)
+			got, _ := Parse(trivia)
+
+			wantJSON, _ := json.Marshal(want)
+			gotJSON, _ := json.Marshal(got)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("AST differs when the token stream contains trivia")
+			}
+		})
+	}
+}
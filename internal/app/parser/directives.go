@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+)
+
+// DirectivePrefix starts the comments that are directives, after the # and any spaces
+const DirectivePrefix = "gock3:"
+
+// Directives returns the directives among the comments of the stream, in source order
+func Directives(stream *tokens.TokenStream) []*ast.Directive {
+	var directives []*ast.Directive
+
+	// Lines that have syntax before the comment, which makes it a trailing one
+	code := make(map[int]int)
+	for _, token := range stream.Tokens {
+		switch token.Type {
+		case tokens.COMMENT, tokens.NEXTLINE, tokens.WHITESPACE, tokens.TAB:
+			continue
+		}
+		line := int(token.Loc.Line)
+		if start, ok := code[line]; !ok || token.Span.Start < start {
+			code[line] = token.Span.Start
+		}
+	}
+
+	for _, comment := range stream.Comments {
+		text := strings.TrimSpace(strings.TrimLeft(comment.Value, "#"))
+		text, ok := strings.CutPrefix(text, DirectivePrefix)
+		if !ok {
+			continue
+		}
+
+		words := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(words) == 0 {
+			words = []string{""}
+		}
+
+		start, hasCode := code[int(comment.Loc.Line)]
+		directives = append(directives, &ast.Directive{
+			Comment: comment,
+			Name:    words[0],
+			Args:    words[1:],
+			OwnLine: !hasCode || start > comment.Span.Start,
+		})
+	}
+
+	return directives
+}
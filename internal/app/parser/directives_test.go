@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+)
+
+func TestDirectives(t *testing.T) {
+	type directive struct {
+		Line    uint32
+		Name    string
+		Args    []string
+		OwnLine bool
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []directive
+	}{
+		{
+			name: "own line",
+			text: "# gock3:ignore trait/out-of-range\na = b\n",
+			want: []directive{{1, "ignore", []string{"trait/out-of-range"}, true}},
+		},
+		{
+			name: "after a field",
+			text: "a = b # gock3:ignore trait/out-of-range\n",
+			want: []directive{{1, "ignore", []string{"trait/out-of-range"}, false}},
+		},
+		{
+			name: "indented",
+			text: "a = {\n\t#gock3:ignore\n\tb = c\n}\n",
+			want: []directive{{2, "ignore", []string{}, true}},
+		},
+		{
+			name: "several rules",
+			text: "## gock3: ignore-file  trait, character/duplicate,parse/missing-value\n",
+			want: []directive{{1, "ignore-file", []string{"trait", "character/duplicate", "parse/missing-value"}, true}},
+		},
+		{
+			name: "ordinary comments",
+			text: "# ignore trait\na = b # gock3 ignore\n",
+			want: nil,
+		},
+		{
+			// Reported as an unknown directive rather than passed over
+			name: "without a name",
+			text: "a = b # gock3:\n",
+			want: []directive{{1, "", []string{}, false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.txt")
+			if err := os.WriteFile(path, []byte(tt.text), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream, _ := lexer.Scan(entry, []byte(tt.text))
+
+			var got []directive
+			for _, d := range Directives(stream) {
+				got = append(got, directive{d.Comment.Loc.Line, d.Name, d.Args, d.OwnLine})
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Directives() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
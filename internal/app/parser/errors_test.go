@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+func TestParse_RuleIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []rules.ID
+	}{
+		{"valid", "a = { b = c }", nil},
+		{"unclosed block", "a = {\n\tb = c\n", []rules.ID{rules.ParseUnclosedBlock}},
+		{"unmatched brace", "a = b\n}\nc = d", []rules.ID{rules.ParseUnmatchedBrace}},
+		{"missing value", "a =\nb = c", []rules.ID{rules.ParseMissingValue}},
+		{"missing operator", "a b", []rules.ID{rules.ParseMissingOperator, rules.ParseMissingOperator}},
+		{"invalid date", "a = 1.13.1", []rules.ID{rules.ParseInvalidDate}},
+		{"unexpected character", "a = я\nb = c", []rules.ID{rules.LexUnexpectedCharacter, rules.ParseMissingValue}},
+		{"comparison with a block", "a == { b = c }", []rules.ID{rules.ParseComparisonWithBlock}},
+		{"comparison with a value", "a == b", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := parseTextWithErrors(t, tt.text)
+
+			var got []rules.ID
+			for _, err := range errs {
+				if _, ok := rules.Lookup(err.Rule); !ok {
+					t.Errorf("%q has the unregistered rule %q", err.Msg, err.Rule)
+				}
+				got = append(got, err.Rule)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rules = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParse_BrokenSyntaxRuleIDs checks that the diagnostics of badly broken syntax have registered rules
+func TestParse_BrokenSyntaxRuleIDs(t *testing.T) {
+	for _, text := range []string{"a = { b = c", "}}}", "= = =", "a = \"\\q\"", "a { b } = c ="} {
+		_, errs := parseTextWithErrors(t, text)
+		for _, err := range errs {
+			if _, ok := rules.Lookup(err.Rule); !ok {
+				t.Errorf("%q: %q has the unregistered rule %q", text, err.Msg, err.Rule)
+			}
+		}
+	}
+}
+
+func TestParse_UnclosedBlockPointsAtOpeningBrace(t *testing.T) {
+	_, errs := parseTextWithErrors(t, "a = {\n\tb = {\n\t\tc = d\n\t}\n")
+	if len(errs) != 1 || errs[0].Rule != rules.ParseUnclosedBlock {
+		t.Fatalf("diagnostics = %v, want a single %s", errs, rules.ParseUnclosedBlock)
+	}
+
+	related := errs[0].Related
+	if len(related) != 1 {
+		t.Fatalf("got %d related locations, want 1", len(related))
+	}
+	if loc := related[0].Pointer.Loc; loc.Line != 1 || loc.Column != 5 || related[0].Pointer.Length != 1 {
+		t.Errorf("related location = %d:%d, length %d, want the brace at 1:5", loc.Line, loc.Column, related[0].Pointer.Length)
+	}
+}
+
+func TestParse_ComparisonWithBlockFix(t *testing.T) {
+	_, errs := parseTextWithErrors(t, "a = {\n\tb == { c = d }\n}\n")
+	if len(errs) != 1 || len(errs[0].Fixes) != 1 {
+		t.Fatalf("diagnostics = %v, want one with a fix", errs)
+	}
+
+	edits := errs[0].Fixes[0].Edits
+	if len(edits) != 1 || edits[0].Span != (tokens.Span{Start: 9, End: 11}) || edits[0].NewText != "=" {
+		t.Errorf("edits = %+v, want `==` at 9:11 replaced with `=`", edits)
+	}
+}
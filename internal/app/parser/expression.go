@@ -2,24 +2,27 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/pkg/date"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
 // fileBlock parses the entire file and constructs the AST's FileBlock.
 func (p *Parser) fileBlock() *ast.FileBlock {
 	if p.currentToken == nil {
 		// Empty file
-		return &ast.FileBlock{Values: []*ast.Field{}, Loc: tokens.Loc{}}
+		return &ast.FileBlock{Values: []*ast.Field{}, Loc: tokens.Loc{}, Comments: p.eofComments}
 	}
 
 	loc := p.loc
 	fields := p.FieldList()
-	return &ast.FileBlock{Values: fields, Loc: *loc}
+	comments := append(p.takeComments(), p.eofComments...)
+	return &ast.FileBlock{Values: fields, Loc: *loc, Comments: comments, Errors: p.takeErrorNodes()}
 }
 
 // FieldList parses a list of fields until a stop token is encountered.
@@ -42,19 +45,21 @@ func (p *Parser) FieldList(stopLookahead ...tokens.TokenType) []*ast.Field {
 				fields = append(fields, field)
 			}
 		default:
+			// A stray closing brace is a recovery point itself, so it has to be skipped explicitly
+			if p.currentToken.Type == tokens.END {
+				p.AddError(report.FromToken(p.currentToken, rules.ParseUnmatchedBrace, errUnmatchedBrace))
+				p.skipToken()
+				continue
+			}
+
 			// Handle unexpected token
 			errMsg := fmt.Sprintf(errFieldListUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-			err := report.FromToken(p.currentToken, severity.Error, errMsg)
-
-			path, e := p.currentToken.Loc.Fullpath()
-			if e != nil {
-				return nil
-			}
-			panic(path)
+			err := report.FromToken(p.currentToken, rules.ParseUnexpectedToken, errMsg)
 			p.AddError(err)
-			if _, recovered := p.synchronize(FieldListRecovery); !recovered {
-				return fields // Stop parsing if recovery fails
-			}
+
+			// synchronize records the tokens it skipped in an error node even when it gives up,
+			// so the rest of the file is still parsed, a few tokens further
+			p.synchronize(FieldListRecovery)
 		}
 	}
 
@@ -68,7 +73,7 @@ func (p *Parser) Field() *ast.Field {
 		return p.ExpressionNode()
 	default:
 		errMsg := fmt.Sprintf(errUnexpectedFieldToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, rules.ParseUnexpectedToken, errMsg)
 		p.AddError(err)
 
 		if _, recovered := p.synchronize(FieldRecovery); !recovered {
@@ -80,43 +85,56 @@ func (p *Parser) Field() *ast.Field {
 
 // ExpressionNode parses an expression node and returns the corresponding AST node.
 func (p *Parser) ExpressionNode() *ast.Field {
-	key := p.Key()
-	if key == nil {
-		return nil
+	field := &ast.Field{LeadingComments: p.takeComments()}
+
+	field.Key = p.Key()
+	if field.Key == nil {
+		return p.dropField(field)
 	}
 
-	operator := p.Operator()
-	if operator == nil {
-		return nil
+	field.Operator = p.Operator()
+	if field.Operator == nil {
+		return p.dropField(field)
 	}
 
-	value := p.Value()
-	if value == nil {
-		return nil
+	// The field owns the comments found while parsing its value,
+	// unless a nested field claims them first
+	owner := p.owner
+	p.owner = field
+	field.Value = p.Value()
+	p.owner = owner
+
+	if field.Value == nil {
+		return p.dropField(field)
 	}
 
-	return &ast.Field{
-		Key:      key,
-		Operator: operator,
-		Value:    value,
+	// A block is assigned, never compared
+	if _, isBlock := field.Value.(ast.Block); isBlock && field.Operator.Value == "==" {
+		err := report.FromToken(field.Operator, rules.ParseComparisonWithBlock, errComparisonWithBlock).
+			WithFix("replace `==` with `=`", report.ReplaceToken(field.Operator, "="))
+		p.AddError(err)
 	}
+
+	field.TrailingComments = append(field.TrailingComments, p.takeTrailingComments()...)
+
+	return field
 }
 
 // Key parses the key of a field and returns the corresponding token.
 func (p *Parser) Key() *tokens.Token {
 	if p.currentToken == nil {
 		errMsg := "Expected a key, but reached end of input"
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
+		err := report.FromLoc(*p.loc, rules.ParseMissingKey, errMsg)
 		p.AddError(err)
 		return nil
 	}
 
 	switch p.currentToken.Type {
 	case tokens.WORD, tokens.DATE, tokens.NUMBER:
-		return p.Expect(tokens.WORD, tokens.DATE, tokens.NUMBER)
+		return p.checkDate(p.Expect(tokens.WORD, tokens.DATE, tokens.NUMBER))
 	default:
 		errMsg := fmt.Sprintf("Expected a key (WORD, DATE, or NUMBER), but found %q of type %q", p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, rules.ParseMissingKey, errMsg)
 		p.AddError(err)
 
 		if _, recovered := p.synchronize(KeyRecovery); !recovered {
@@ -130,7 +148,7 @@ func (p *Parser) Key() *tokens.Token {
 func (p *Parser) Operator() *tokens.Token {
 	if p.currentToken == nil {
 		errMsg := errOperatorExpectedEOF
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
+		err := report.FromLoc(*p.loc, rules.ParseMissingOperator, errMsg)
 		p.AddError(err)
 		return nil
 	}
@@ -144,7 +162,7 @@ func (p *Parser) Operator() *tokens.Token {
 		return p.Expect(tokens.COMPARISON)
 	default:
 		errMsg := fmt.Sprintf(errOperatorUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, rules.ParseMissingOperator, errMsg)
 		p.AddError(err)
 
 		if _, recovered := p.synchronize(ValueRecovery); !recovered {
@@ -156,31 +174,61 @@ func (p *Parser) Operator() *tokens.Token {
 }
 
 // Value parses the value of a field and returns the corresponding AST node.
+// The value may be on a later line than the operator, as the game accepts it.
+// A value is missing when the block or the file ends first, or when the next field starts.
 func (p *Parser) Value() ast.BV {
-	if p.currentToken == nil {
-		errMsg := errValueExpectedEOF
-		err := report.FromLoc(*p.loc, severity.Error, errMsg)
-		p.AddError(err)
-		return nil
+	if p.currentToken != nil && p.currentToken.Type == tokens.NEXTLINE {
+		p.claimOperatorComments()
+		p.skipTokens(tokens.NEXTLINE)
+		p.claimValueComments()
 	}
 
-	switch p.currentToken.Type {
-	case tokens.NEXTLINE:
-		p.Expect(tokens.NEXTLINE)
+	if p.currentToken == nil || p.currentToken.Type == tokens.END || p.isNextField() {
+		p.reportMissingValue()
 		return p.EmptyValue()
+	}
+
+	switch p.currentToken.Type {
 	case tokens.WORD, tokens.NUMBER, tokens.QUOTED_STRING, tokens.BOOL, tokens.DATE:
 		return p.Literal()
 	case tokens.START:
 		return p.Block()
 	default:
 		errMsg := fmt.Sprintf(errValueUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, rules.ParseUnexpectedToken, errMsg)
 		p.AddError(err)
 		p.synchronize(ValueRecovery)
 		return nil
 	}
 }
 
+// claimOperatorComments gives the comments after the operator to the field being parsed
+func (p *Parser) claimOperatorComments() {
+	if p.owner != nil {
+		p.owner.TrailingComments = append(p.owner.TrailingComments, p.takeTrailingComments()...)
+	}
+}
+
+// claimValueComments gives the comments between the operator and a value on a later line
+// to the field being parsed. If the value is missing, they stay for whatever comes next.
+func (p *Parser) claimValueComments() {
+	if p.owner == nil || p.currentToken == nil || p.currentToken.Type == tokens.END || p.isNextField() {
+		return
+	}
+	p.owner.TrailingComments = append(p.owner.TrailingComments, p.takeComments()...)
+}
+
+// reportMissingValue reports a field without a value at its operator
+func (p *Parser) reportMissingValue() {
+	if p.owner == nil || p.owner.Operator == nil {
+		p.AddError(report.FromLoc(*p.loc, rules.ParseMissingValue, errValueMissing))
+		return
+	}
+
+	errMsg := fmt.Sprintf(errValueMissingForKey, p.owner.Key.Value)
+	p.AddError(report.FromToken(p.owner.Operator, rules.ParseMissingValue, errMsg))
+}
+
 // EmptyValue returns an empty value AST node.
 func (p *Parser) EmptyValue() ast.BV {
 	return ast.EmptyValue{
@@ -191,14 +239,14 @@ func (p *Parser) EmptyValue() ast.BV {
 // Literal parses a literal token and returns the corresponding token.
 func (p *Parser) Literal() *tokens.Token {
 	if p.currentToken == nil {
-		err := report.FromLoc(*p.loc, severity.Error, errLiteralExpectedEOF)
+		err := report.FromLoc(*p.loc, rules.ParseUnexpectedEOF, errLiteralExpectedEOF)
 		p.AddError(err)
 		return nil
 	}
 
 	switch p.currentToken.Type {
 	case tokens.WORD, tokens.NUMBER, tokens.BOOL, tokens.DATE:
-		if token := p.Expect(p.currentToken.Type); token != nil {
+		if token := p.checkDate(p.Expect(p.currentToken.Type)); token != nil {
 			return token
 		}
 	case tokens.QUOTED_STRING:
@@ -207,7 +255,7 @@ func (p *Parser) Literal() *tokens.Token {
 		}
 	default:
 		errMsg := fmt.Sprintf(errLiteralUnexpectedToken, p.currentToken.Value, p.currentToken.Type)
-		err := report.FromToken(p.currentToken, severity.Error, errMsg)
+		err := report.FromToken(p.currentToken, rules.ParseUnexpectedToken, errMsg)
 		p.AddError(err)
 	}
 
@@ -219,9 +267,23 @@ func (p *Parser) Literal() *tokens.Token {
 		}
 		// If recovered to a non-literal token, give up
 		errMsg := fmt.Sprintf(errRecoveredNonLiteralToken, token.Value, token.Type)
-		err := report.FromToken(token, severity.Error, errMsg)
+		err := report.FromToken(token, rules.ParseNonLiteralValue, errMsg)
 		p.AddError(err)
 	}
 
 	return nil
 }
+
+// checkDate reports date tokens that don't exist in the calendar, such as 867.13.40.
+// The token is returned either way, since it's still a valid key or value.
+func (p *Parser) checkDate(token *tokens.Token) *tokens.Token {
+	if token == nil || token.Type != tokens.DATE {
+		return token
+	}
+
+	var dateErr *date.Error
+	if _, err := date.Parse(token.Value); errors.As(err, &dateErr) {
+		p.AddError(report.FromToken(token, rules.ParseInvalidDate, fmt.Sprintf(errInvalidDate, token.Value, dateErr.Reason)))
+	}
+	return token
+}
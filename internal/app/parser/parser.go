@@ -13,19 +13,30 @@ type Parser struct {
 	lookahead    *tokens.Token
 	loc          *tokens.Loc
 	*report.ErrorManager
+
+	// Comment trivia, see comments.go
+	previous    *tokens.Token
+	comments    map[*tokens.Token][]*tokens.Token
+	eofComments []*tokens.Token
+	pending     []*tokens.Token
+	owner       *ast.Field
+
+	// Tokens skipped during error recovery, see recovery.go
+	errorNodes []*ast.ErrorNode
 }
 
 // New creates a new Parser instance.
 func New(tokenstream *tokens.TokenStream) *Parser {
 	p := &Parser{
-		tokenstream:  tokenstream,
 		ErrorManager: report.NewErrorManager(),
 	}
+	p.tokenstream, p.comments, p.eofComments = splitComments(tokenstream)
 	p.currentToken = p.tokenstream.Next()
 	p.lookahead = p.tokenstream.Next()
 	if p.currentToken != nil {
 		p.loc = &p.currentToken.Loc
 	}
+	p.collectComments()
 	return p
 }
 
@@ -38,9 +49,13 @@ func Parse(token_stream *tokens.TokenStream) (*ast.FileBlock, []*report.Diagnost
 
 // nextToken advances the currentToken and lookahead tokens.
 func (p *Parser) nextToken() {
+	if p.currentToken != nil && p.currentToken.Type != tokens.NEXTLINE {
+		p.previous = p.currentToken
+	}
 	p.currentToken = p.lookahead
 	p.lookahead = p.tokenstream.Next()
 	if p.currentToken != nil {
 		p.loc = &p.currentToken.Loc
 	}
+	p.collectComments()
 }
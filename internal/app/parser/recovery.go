@@ -5,8 +5,9 @@ import (
 	"strings"
 
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
 // Recovery mode constants
@@ -89,6 +90,7 @@ func (p *Parser) synchronize(point RecoveryPoint) (*tokens.Token, bool) {
 				// Found recovery point - report skipped section.
 				if len(skipped) > 0 {
 					p.reportSkippedSection(startLoc, skipped, point.Context)
+					p.addErrorNode(skipped)
 				}
 				return p.currentToken, true
 			}
@@ -102,9 +104,31 @@ func (p *Parser) synchronize(point RecoveryPoint) (*tokens.Token, bool) {
 
 	// Failed to recover.
 	p.reportRecoveryFailure(startLoc, point.Context)
+	if len(skipped) > 0 {
+		p.addErrorNode(skipped)
+	}
 	return nil, false
 }
 
+// skipToken skips the current token when it can't be parsed at this point,
+// but would stop synchronize right away, such as a closing brace at file level.
+func (p *Parser) skipToken() {
+	p.addErrorNode([]*tokens.Token{p.currentToken})
+	p.nextToken()
+}
+
+// addErrorNode records skipped tokens until the enclosing block claims them.
+func (p *Parser) addErrorNode(skipped []*tokens.Token) {
+	p.errorNodes = append(p.errorNodes, ast.NewErrorNode(skipped))
+}
+
+// takeErrorNodes claims all recorded error nodes.
+func (p *Parser) takeErrorNodes() []*ast.ErrorNode {
+	nodes := p.errorNodes
+	p.errorNodes = nil
+	return nodes
+}
+
 // reportSkippedSection reports the tokens that were skipped during recovery.
 func (p *Parser) reportSkippedSection(startLoc tokens.Loc, skipped []*tokens.Token, context string) {
 	var skippedValues []string
@@ -118,7 +142,7 @@ func (p *Parser) reportSkippedSection(startLoc tokens.Loc, skipped []*tokens.Tok
 		strings.Join(skippedValues, ", "),
 	)
 
-	err := report.FromLoc(startLoc, severity.Warning, errMsg)
+	err := report.FromLoc(startLoc, rules.ParseSkippedSyntax, errMsg)
 	p.AddError(err)
 }
 
@@ -128,6 +152,6 @@ func (p *Parser) reportRecoveryFailure(startLoc tokens.Loc, context string) {
 		"Failed to recover while parsing %s - too many invalid tokens",
 		context,
 	)
-	err := report.FromLoc(startLoc, severity.Error, errMsg)
+	err := report.FromLoc(startLoc, rules.ParseRecoveryFailed, errMsg)
 	p.AddError(err)
 }
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+)
+
+func fieldKeys(fields []*ast.Field) []string {
+	keys := make([]string, len(fields))
+	for i, field := range fields {
+		keys[i] = field.Key.Value
+	}
+	return keys
+}
+
+func skippedText(text string, nodes []*ast.ErrorNode) []string {
+	result := make([]string, len(nodes))
+	for i, node := range nodes {
+		result[i] = text[node.Span.Start:node.Span.End]
+	}
+	return result
+}
+
+func TestParse_ErrorNodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		keys    []string
+		skipped []string
+	}{
+		{"stray closing brace", "a = b\n}\nc = d", []string{"a", "c"}, []string{"}"}},
+		{"stray closing braces", "}}a = b", []string{"a"}, []string{"}", "}"}},
+		{"stray operator", "= x = y\nc = d", []string{"x", "c"}, []string{"="}},
+		{"stray string", "\"text\" a = b", []string{"a"}, []string{"\"text\""}},
+		{
+			name:    "more stray tokens than recovery attempts",
+			text:    strings.Repeat("= ", maxRecoveryAttempts+2) + "a = b",
+			keys:    []string{"a"},
+			skipped: []string{strings.TrimSpace(strings.Repeat("= ", maxRecoveryAttempts)), "= ="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, errs := parseTextWithErrors(t, tt.text)
+			if len(errs) == 0 {
+				t.Errorf("expected diagnostics for the skipped syntax")
+			}
+
+			if got := fieldKeys(file.Values); !reflect.DeepEqual(got, tt.keys) {
+				t.Errorf("fields = %q, want %q", got, tt.keys)
+			}
+			if got := skippedText(tt.text, file.Errors); !reflect.DeepEqual(got, tt.skipped) {
+				t.Errorf("skipped = %q, want %q", got, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestParse_ErrorNodesInBlock(t *testing.T) {
+	text := "a = {\n\tb = c\n\t== d = e\n}\nf = g"
+	file, _ := parseTextWithErrors(t, text)
+
+	if got := fieldKeys(file.Values); !reflect.DeepEqual(got, []string{"a", "f"}) {
+		t.Fatalf("fields = %q", got)
+	}
+
+	block := file.GetFieldBlock("a")
+	if block == nil {
+		t.Fatalf("a is %T, want *ast.FieldBlock", file.GetField("a").Value)
+	}
+	if got := fieldKeys(block.Values); !reflect.DeepEqual(got, []string{"b", "d"}) {
+		t.Errorf("block fields = %q", got)
+	}
+	if got := skippedText(text, block.Errors); !reflect.DeepEqual(got, []string{"=="}) {
+		t.Errorf("skipped in block = %q", got)
+	}
+	if len(file.Errors) != 0 {
+		t.Errorf("error nodes of the block ended up in the file: %q", skippedText(text, file.Errors))
+	}
+}
+
+// TestParse_TruncatedFixtures checks that the parser returns a tree for broken files instead of panicking.
+func TestParse_TruncatedFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fixturesDir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stream, _ := lexer.Scan(entry, content)
+		all := stream.Tokens
+
+		// Drop a single token at a few places, which unbalances braces and fields
+		step := max(1, len(all)/16)
+		for i := 0; i < len(all); i += step {
+			broken := tokens.NewTokenStream()
+			for j, token := range all {
+				if j != i {
+					copied := *token
+					broken.Push(&copied)
+				}
+			}
+
+			if block, _ := Parse(broken); block == nil {
+				t.Errorf("%s without token %d: Parse returned no tree", filepath.Base(path), i)
+			}
+		}
+	}
+}
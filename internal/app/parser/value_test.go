@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+)
+
+func TestParse_ValuesOnNextLineFixture(t *testing.T) {
+	path := filepath.Join(fixturesDir, "7_multiline_values.txt")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, lexerErrs := lexer.ScanWithOptions(entry, content, lexer.Options{KeepComments: true})
+	file, parserErrs := Parse(stream)
+	for _, err := range append(lexerErrs, parserErrs...) {
+		t.Errorf("unexpected diagnostic at %d:%d: %s", err.Pointer.Loc.Line, err.Pointer.Loc.Column, err.Msg)
+	}
+
+	if got := file.GetFieldValue("namespace"); got == nil || got.Value != "multiline" {
+		t.Errorf("namespace = %v", got)
+	}
+
+	event := file.GetFieldBlock("multiline.1")
+	if event == nil {
+		t.Fatalf("multiline.1 is %T, want *ast.FieldBlock", file.GetField("multiline.1").Value)
+	}
+
+	values := map[string]string{"type": "character_event", "title": "multiline.1.t", "desc": "multiline.1.desc"}
+	for key, want := range values {
+		if got := event.GetFieldValue(key); got == nil || got.Value != want {
+			t.Errorf("%s = %v, want %q", key, got, want)
+		}
+	}
+
+	if got := commentValues(event.GetField("desc").TrailingComments); len(got) != 1 || got[0] != "# shown in the event window" {
+		t.Errorf("desc comments = %q", got)
+	}
+
+	trigger := event.GetFieldBlock("trigger")
+	if trigger == nil || trigger.GetFieldValue("age").Value != "16" {
+		t.Errorf("trigger = %v", trigger)
+	}
+
+	option := event.GetFieldBlock("option")
+	if option == nil || option.GetFieldValue("name").Value != "multiline.1.a" || len(option.Values) != 2 {
+		t.Errorf("option = %v", option)
+	}
+}
+
+func TestParse_MissingValue(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		// keys of the fields at file level and in block `a`, with an empty value marked by "!"
+		keys      []string
+		errorLine uint32
+	}{
+		{"end of file", "a = b\nc =", []string{"a", "c!"}, 2},
+		{"end of file after newlines", "c =\n\n", []string{"c!"}, 1},
+		{"next field", "c =\nd = e", []string{"c!", "d"}, 1},
+		{"next field on the same line", "c = d = e", []string{"c!", "d"}, 1},
+		{"end of block", "a = {\n\tc =\n}", []string{"a", "c!"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, errs := parseTextWithErrors(t, tt.text)
+
+			if len(errs) != 1 {
+				t.Fatalf("got %d diagnostics, want 1: %v", len(errs), errs)
+			}
+			if !strings.Contains(errs[0].Msg, `Expected a value for "c"`) || errs[0].Pointer.Loc.Line != tt.errorLine {
+				t.Errorf("diagnostic = %q at line %d, want a missing value at line %d", errs[0].Msg, errs[0].Pointer.Loc.Line, tt.errorLine)
+			}
+
+			fields := file.Values
+			if block := file.GetFieldBlock("a"); block != nil {
+				fields = append(fields, block.Values...)
+			}
+
+			var keys []string
+			for _, field := range fields {
+				key := field.Key.Value
+				if _, empty := field.Value.(ast.EmptyValue); empty {
+					key += "!"
+				}
+				keys = append(keys, key)
+			}
+			if strings.Join(keys, " ") != strings.Join(tt.keys, " ") {
+				t.Errorf("fields = %q, want %q", keys, tt.keys)
+			}
+		})
+	}
+}
+
+// TestParse_ValueOnNextLineMatchesSameLine checks that moving values to the next line doesn't change the AST.
+func TestParse_ValueOnNextLineMatchesSameLine(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(fixturesDir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sameLine, _ := lexer.Scan(entry, content)
+			nextLine, _ := lexer.Scan(entry, content)
+
+			// Put a newline after every operator that is followed by a value
+			moved := tokens.NewTokenStream()
+			for i, token := range nextLine.Tokens {
+				moved.Push(token)
+				if isOperatorToken(token.Type) && i+1 < len(nextLine.Tokens) && nextLine.Tokens[i+1].Type != tokens.NEXTLINE {
+					moved.Push(&tokens.Token{Value: "\n", Type: tokens.NEXTLINE, Loc: token.Loc})
+				}
+			}
+
+			want, _ := Parse(sameLine)
+			got, _ := Parse(moved)
+			if countFields(got) != countFields(want) {
+				t.Errorf("got %d fields, want %d", countFields(got), countFields(want))
+			}
+		})
+	}
+}
+
+func countFields(value ast.BV) int {
+	switch b := value.(type) {
+	case *ast.FieldBlock:
+		count := len(b.Values)
+		for _, field := range b.Values {
+			count += countFields(field.Value)
+		}
+		return count
+	case *ast.MixedBlock:
+		count := 0
+		for _, item := range b.Items {
+			if item.Field != nil {
+				count += 1 + countFields(item.Field.Value)
+			} else {
+				count += countFields(item.Value)
+			}
+		}
+		return count
+	default:
+		return 0
+	}
+}
+
+func TestParse_InvalidDates(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"valid dates", "1066.9.15 = { birth = 1066.9.15 }\n776.1. = { death = yes }", nil},
+		{"invalid key", "867.13.40 = { birth = yes }", []string{`Invalid date "867.13.40": month 13 is not between 1 and 12`}},
+		{"invalid value", "c = { birth = 1000.2.29 }", []string{`Invalid date "1000.2.29": day 29 is not between 1 and 28`}},
+		{"invalid list item", "dates = { 1.1.1 1.4.31 }", []string{`Invalid date "1.4.31": day 31 is not between 1 and 30`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := parseTextWithErrors(t, tt.text)
+
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Msg)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("diagnostics = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
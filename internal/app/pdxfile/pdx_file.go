@@ -2,29 +2,34 @@ package pdxfile
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 
-	"github.com/fatih/color"
 	"github.com/unLomTrois/gock3/internal/app/files"
 	"github.com/unLomTrois/gock3/internal/app/lexer"
 	"github.com/unLomTrois/gock3/internal/app/parser"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/internal/app/parser/cst"
 	"github.com/unLomTrois/gock3/internal/app/utils"
-	"github.com/unLomTrois/gock3/pkg/cache"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
 )
 
-func ParseFile(entry *files.FileEntry) (*ast.AST, error) {
-	content, err := utils.ReadFileWithUTF8BOM(entry.FullPath())
+// ParseFile parses a file into its AST.
+// The syntax errors found on the way are returned as diagnostics, the error is only for failing to read the file.
+func ParseFile(entry *files.FileEntry) (*ast.AST, []*report.DiagnosticItem, error) {
+	return ParseFileWithOptions(entry, lexer.Options{})
+}
+
+// ParseFileWithOptions is like ParseFile, but lets the caller configure the lexer,
+// e.g. to keep comments in the AST
+func ParseFileWithOptions(entry *files.FileEntry, options lexer.Options) (*ast.AST, []*report.DiagnosticItem, error) {
+	content, err := entry.ReadFile()
 	if err != nil {
-		return nil, fmt.Errorf("reading file: %w", err)
+		return nil, nil, fmt.Errorf("reading file: %w", err)
 	}
+	content, _ = utils.SplitUTF8BOM(content)
 
 	var errs []*report.DiagnosticItem
 
-	token_stream, lexer_errs := lexer.Scan(entry, content)
+	token_stream, lexer_errs := lexer.ScanWithOptions(entry, content, options)
 
 	// utils.SaveJSON(token_stream, "tokenstream.json")
 
@@ -34,56 +39,26 @@ func ParseFile(entry *files.FileEntry) (*ast.AST, error) {
 	errs = append(errs, parser_errs...)
 
 	ast := &ast.AST{
-		Filename: entry.FileName(),
-		Fullpath: entry.FullPath(),
-		Block:    file_block,
+		Filename:   entry.FileName(),
+		Fullpath:   entry.FullPath(),
+		Block:      file_block,
+		Directives: parser.Directives(token_stream),
 	}
 
-	finalize(errs)
-
-	return ast, nil
+	return ast, errs, nil
 }
 
-func finalize(errs []*report.DiagnosticItem) {
-
-	file_cache := cache.NewFileCache()
-
-	for _, err := range errs {
-		var c *color.Color
-		switch err.Severity {
-		case severity.Error:
-			c = color.New(color.FgRed)
-		case severity.Warning:
-			c = color.New(color.FgYellow)
-		case severity.Info:
-			c = color.New(color.FgCyan)
-		case severity.Critical:
-			c = color.New(color.FgHiMagenta)
-		}
-		filename, _ := err.Pointer.Loc.Filename()
-		column := err.Pointer.Loc.Column
-		line := err.Pointer.Loc.Line
-
-		err_line := getErrorLine(file_cache, err, column)
-
-		if err.Pointer.Loc.Line == 1 && err.Pointer.Loc.Column == 1 {
-			c.Println(fmt.Sprintf("[%s:%d:%d]: %s", filename, line, column, err.Msg))
-
-			continue
-		}
-
-		c.Println(fmt.Sprintf("[%s:%d:%d]: %s, got %s", filename, line, column, err.Msg, strconv.Quote(err_line)))
+// ParseConcreteFile reads a file into its lossless concrete syntax tree,
+// which prints back to the exact content of the file
+func ParseConcreteFile(entry *files.FileEntry) (*cst.Tree, error) {
+	content, err := entry.ReadFile()
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
 	}
-}
-
-func getErrorLine(fileCache *cache.FileCache, err *report.DiagnosticItem, column uint16) string {
-	lineStart := fileCache.GetLine(&err.Pointer.Loc)
 
-	// replace tabs to spaces, because loc sees \t as 4 symbols...
-	// todo: do something
-	spacedLine := strings.ReplaceAll(lineStart, "\t", "    ")
+	content, bom := utils.SplitUTF8BOM(content)
 
-	errorEndIndex := column + uint16(err.Pointer.Length) - 1
+	token_stream, _ := lexer.ScanWithOptions(entry, content, lexer.Options{KeepTrivia: true})
 
-	return spacedLine[:errorEndIndex]
+	return &cst.Tree{BOM: bom, Root: parser.ParseCST(token_stream)}, nil
 }
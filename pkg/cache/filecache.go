@@ -1,14 +1,19 @@
 package cache
 
 import (
-	"os"
+	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/unLomTrois/gock3/internal/app/files"
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/utils"
 )
 
+// FileCache keeps the content of the files that diagnostics point at, so that every file is read once.
+// It is safe for concurrent use.
 type FileCache struct {
+	mu    sync.Mutex
 	cache map[files.PathTableIndex]string
 
 	linecache *LineCache
@@ -22,50 +27,69 @@ func NewFileCache() *FileCache {
 }
 
 func (f *FileCache) Get(pathTableIndex files.PathTableIndex) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	content, ok := f.cache[pathTableIndex]
 	return content, ok
 }
 
-func (f *FileCache) Add(index files.PathTableIndex) {
-	fullpath, err := files.PATHTABLE.LookupFullpath(index)
-	if err != nil {
-		panic(err)
-	}
-
-	// read file!
-	content, err := os.ReadFile(fullpath)
+// Add reads the file into the cache, from the file system it was found in.
+// The byte order mark is dropped, since locations don't count it.
+func (f *FileCache) Add(index files.PathTableIndex) error {
+	content, err := index.ReadFile()
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("reading file: %w", err)
 	}
+	content, _ = utils.SplitUTF8BOM(content)
 
-	f.cache[index] = string(content)
+	f.Set(index, string(content))
+	return nil
 }
 
 func (f *FileCache) Set(index files.PathTableIndex, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	f.cache[index] = value
+	delete(f.linecache.cache, index)
 }
 
-func (f *FileCache) GetLine(loc *tokens.Loc) string {
-	index := loc.GetIdx()
+// Lines returns the lines of the file without their line endings, reading the file if needed
+func (f *FileCache) Lines(index files.PathTableIndex) ([]string, error) {
+	f.mu.Lock()
+	lines, ok := f.linecache.Get(index)
+	content, hasContent := f.cache[index]
+	f.mu.Unlock()
 
-	// check lines cache
-	if lines, ok := f.linecache.Get(index); ok {
-		return lines[loc.Line-1]
+	if ok {
+		return lines, nil
 	}
 
-	// check filecache and fill linecache
-	if content, ok := f.Get(index); ok {
-		// split content by \n
-		lines := strings.Split(content, "\n")
+	if !hasContent {
+		if err := f.Add(index); err != nil {
+			return nil, err
+		}
+		content, _ = f.Get(index)
+	}
 
-		f.linecache.Set(index, lines)
-		return lines[loc.Line-1]
+	lines = strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
 	}
 
-	// if nothing found, fill filecahce
-	f.Add(index)
-	// log.Println("skip")
+	f.mu.Lock()
+	f.linecache.Set(index, lines)
+	f.mu.Unlock()
+
+	return lines, nil
+}
 
-	// recursive call
-	return f.GetLine(loc)
+// GetLine returns the line of loc, or an empty string if the file can't be read or is shorter
+func (f *FileCache) GetLine(loc *tokens.Loc) string {
+	lines, err := f.Lines(loc.GetIdx())
+	if err != nil || loc.Line < 1 || int(loc.Line) > len(lines) {
+		return ""
+	}
+	return lines[loc.Line-1]
 }
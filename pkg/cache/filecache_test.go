@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+)
+
+func TestFileCache_GetLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte("\uFEFFa = b\r\nc = d\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := *tokens.LocFromFileEntry(entry)
+
+	tests := []struct {
+		name string
+		line uint32
+		want string
+	}{
+		{"first line without the byte order mark", 1, "a = b"},
+		{"line ending dropped", 2, "c = d"},
+		{"empty last line", 3, ""},
+		{"past the end of the file", 40, ""},
+		{"before the first line", 0, ""},
+	}
+
+	cache := NewFileCache()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc.Line = tt.line
+			if got := cache.GetLine(&loc); got != tt.want {
+				t.Errorf("GetLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCache_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte("a = b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := *tokens.LocFromFileEntry(entry)
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewFileCache()
+	if _, err := cache.Lines(loc.GetIdx()); err == nil {
+		t.Errorf("Lines() of a missing file returned no error")
+	}
+	if got := cache.GetLine(&loc); got != "" {
+		t.Errorf("GetLine() of a missing file = %q", got)
+	}
+}
+
+func TestFileCache_VirtualFile(t *testing.T) {
+	fsys := fstest.MapFS{"mod/common/traits/t.txt": {Data: []byte("a = b\nc = d\n")}}
+	entry, err := files.NewFileEntryFS(nil, fsys, "mod/common/traits/t.txt", "unsaved/t.txt", files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := *tokens.LocFromFileEntry(entry)
+	loc.Line = 2
+
+	if got := NewFileCache().GetLine(&loc); got != "c = d" {
+		t.Errorf("GetLine() = %q, want %q", got, "c = d")
+	}
+	if path, _ := loc.Pathname(); path != "unsaved/t.txt" {
+		t.Errorf("Pathname() = %q, want the path given to the entry", path)
+	}
+}
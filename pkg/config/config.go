@@ -0,0 +1,302 @@
+// Package config reads the settings of a project from .gock3.txt, a file at the root of the mod
+// written in PDXScript, which the project command finds from the --mod flags or in the current directory:
+//
+//	game = "C:/Program Files (x86)/Steam/steamapps/common/Crusader Kings III/game"
+//	mod = "../my_mod.mod"
+//	mod = "../my_patch.mod"
+//	format = sarif
+//
+//	rules = {
+//		enable = { trait character parse }
+//		disable = { "character/duplicate" }
+//		severity = {
+//			warning = { "trait/out-of-range" }
+//			critical = { parse }
+//		}
+//	}
+//
+//	ignore = { "common/traits/00_legacy.txt" "history/characters/*" }
+//
+// Relative game and mod paths are relative to the directory of the file, and mod may be given for every mod of the playset.
+// Rules are given by ID or by category, and IDs need quotes since they contain a slash.
+// With enable only the rules it selects are reported, and disable leaves rules out of those.
+// Ignored paths are patterns of path.Match relative to the root of the mod or of the game,
+// and a pattern that matches a folder ignores everything in it.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/internal/app/pdxfile"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// FileName is the name of the config file, looked up at the root of the mod, see Find
+const FileName = ".gock3.txt"
+
+// Find returns the config file of the first folder that has one, such as the root of the mod
+func Find(folders ...string) (string, bool) {
+	for _, folder := range folders {
+		path := filepath.Join(folder, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Config is the settings of a project. Settings missing from the file are empty.
+type Config struct {
+	// Path is the file the config was read from
+	Path    string
+	GameDir string
+	// ModDescriptors are the mods of the playset, in the order of the file
+	ModDescriptors []string
+	Format         string
+
+	// Enable selects the only rules that are reported, when it is not empty
+	Enable []string
+	// Disable leaves rules out
+	Disable []string
+	// Severities change the severity of rules, the last matching override wins
+	Severities []SeverityOverride
+	// Ignore are the patterns of the paths whose diagnostics are left out
+	Ignore []string
+}
+
+// SeverityOverride is the severity of the rules selected by a pattern
+type SeverityOverride struct {
+	Pattern  string
+	Severity severity.Severity
+}
+
+// Load reads the config file. Unknown fields and invalid values are left out and reported as diagnostics,
+// the error is only for failing to read the file.
+func Load(path string) (*Config, []*report.DiagnosticItem, error) {
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	tree, diagnostics, err := pdxfile.ParseFile(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := &loader{config: &Config{Path: path}, diagnostics: diagnostics}
+	l.load(tree.Block)
+
+	return l.config, l.diagnostics, nil
+}
+
+type loader struct {
+	config      *Config
+	diagnostics []*report.DiagnosticItem
+}
+
+func (l *loader) load(block *ast.FieldBlock) {
+	dir := filepath.Dir(l.config.Path)
+
+	for _, field := range block.Values {
+		switch field.Key.Value {
+		case "game":
+			if token := l.string(field); token != nil {
+				l.config.GameDir = resolve(dir, token.Value)
+			}
+		case "mod":
+			if token := l.string(field); token != nil {
+				l.config.ModDescriptors = append(l.config.ModDescriptors, resolve(dir, token.Value))
+			}
+		case "format":
+			if token := l.string(field); token != nil {
+				l.config.Format = token.Value
+			}
+		case "rules":
+			if rulesBlock := l.block(field); rulesBlock != nil {
+				l.loadRules(rulesBlock)
+			}
+		case "ignore":
+			for _, token := range l.list(field) {
+				l.config.Ignore = append(l.config.Ignore, token.Value)
+			}
+		default:
+			l.unknown(field, "game, mod, format, rules and ignore")
+		}
+	}
+}
+
+func (l *loader) loadRules(block *ast.FieldBlock) {
+	for _, field := range block.Values {
+		switch field.Key.Value {
+		case "enable":
+			l.config.Enable = append(l.config.Enable, l.patterns(field)...)
+		case "disable":
+			l.config.Disable = append(l.config.Disable, l.patterns(field)...)
+		case "severity":
+			severities := l.block(field)
+			if severities == nil {
+				continue
+			}
+			for _, level := range severities.Values {
+				s, ok := severity.Parse(level.Key.Value)
+				if !ok {
+					l.invalid(level.Key, fmt.Sprintf("unknown severity %q, expected info, warning, error or critical", level.Key.Value))
+					continue
+				}
+				for _, pattern := range l.patterns(level) {
+					l.config.Severities = append(l.config.Severities, SeverityOverride{Pattern: pattern, Severity: s})
+				}
+			}
+		default:
+			l.unknown(field, "enable, disable and severity")
+		}
+	}
+}
+
+// string returns the value of a field that should be a word or a quoted string
+func (l *loader) string(field *ast.Field) *tokens.Token {
+	token, ok := field.Value.(*tokens.Token)
+	if !ok || (token.Type != tokens.WORD && token.Type != tokens.QUOTED_STRING) {
+		l.invalid(field.Key, fmt.Sprintf("expected a string for %s", field.Key.Value))
+		return nil
+	}
+	return token
+}
+
+// block returns the value of a field that should be a block of fields
+func (l *loader) block(field *ast.Field) *ast.FieldBlock {
+	block, ok := field.Value.(*ast.FieldBlock)
+	if !ok {
+		l.invalid(field.Key, fmt.Sprintf("expected a block of fields for %s", field.Key.Value))
+		return nil
+	}
+	return block
+}
+
+// list returns the values of a field that should be a block of strings, such as { a "b/c" }
+func (l *loader) list(field *ast.Field) []*tokens.Token {
+	switch value := field.Value.(type) {
+	case *ast.TokenBlock:
+		var result []*tokens.Token
+		for _, token := range value.Values {
+			if token.Type != tokens.WORD && token.Type != tokens.QUOTED_STRING {
+				l.invalid(token, fmt.Sprintf("expected a string, found %q", token.Value))
+				continue
+			}
+			result = append(result, token)
+		}
+		return result
+	case *ast.FieldBlock:
+		// An empty block is parsed as a block of fields
+		if len(value.Values) == 0 {
+			return nil
+		}
+	}
+
+	l.invalid(field.Key, fmt.Sprintf("expected a list of strings for %s, such as { a \"b/c\" }", field.Key.Value))
+	return nil
+}
+
+// patterns returns the rule patterns of a list, leaving out the ones that select no rule
+func (l *loader) patterns(field *ast.Field) []string {
+	var result []string
+	for _, token := range l.list(field) {
+		if !rules.Known(token.Value) {
+			l.diagnostics = append(l.diagnostics, report.FromToken(token, rules.ConfigInvalidValue,
+				fmt.Sprintf("no rule has the ID or category %q", token.Value)).
+				WithHelp("see `gock3 rules` for the rules"))
+			continue
+		}
+		result = append(result, token.Value)
+	}
+	return result
+}
+
+func (l *loader) invalid(token *tokens.Token, msg string) {
+	l.diagnostics = append(l.diagnostics, report.FromToken(token, rules.ConfigInvalidValue, msg))
+}
+
+func (l *loader) unknown(field *ast.Field, expected string) {
+	l.diagnostics = append(l.diagnostics, report.FromToken(field.Key, rules.ConfigUnknownField,
+		fmt.Sprintf("unknown field %q", field.Key.Value)).
+		WithHelp("the fields here are "+expected))
+}
+
+func resolve(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Apply returns the diagnostics of the rules that are reported, outside of the ignored paths,
+// with the severities of the config. Paths are matched relative to the roots.
+func (c *Config) Apply(items []*report.DiagnosticItem, roots ...string) []*report.DiagnosticItem {
+	relative := report.NewRoots(roots...)
+
+	result := make([]*report.DiagnosticItem, 0, len(items))
+	for _, item := range items {
+		if !c.Enabled(item.Rule) {
+			continue
+		}
+
+		if item.Pointer != nil && len(c.Ignore) > 0 {
+			if fullpath, err := item.Pointer.Loc.Pathname(); err == nil {
+				if path, ok := relative.Relative(fullpath); ok && c.Ignored(path) {
+					continue
+				}
+			}
+		}
+
+		for _, override := range c.Severities {
+			if item.Rule.Matches(override.Pattern) {
+				item.Severity = override.Severity
+			}
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// Enabled reports whether the diagnostics of the rule are reported
+func (c *Config) Enabled(rule rules.ID) bool {
+	if len(c.Enable) > 0 && !matchesAny(rule, c.Enable) {
+		return false
+	}
+	return !matchesAny(rule, c.Disable)
+}
+
+func matchesAny(rule rules.ID, patterns []string) bool {
+	for _, pattern := range patterns {
+		if rule.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignored reports whether the path, relative to its root with forward slashes, or one of its folders is ignored
+func (c *Config) Ignored(relative string) bool {
+	for _, pattern := range c.Ignore {
+		pattern = strings.TrimSuffix(pattern, "/")
+
+		// Match the path and then every folder it is in
+		for name := relative; name != "." && name != "/" && name != ""; name = path.Dir(name) {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// writeFile writes the content to the path relative to root and returns the full path
+func writeFile(t *testing.T, root string, path string, content string) string {
+	t.Helper()
+
+	fullpath := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(fullpath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullpath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return fullpath
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name  string
+		text  string
+		want  *Config
+		rules []rules.ID
+	}{
+		{
+			name: "every setting",
+			text: `
+game = "/games/ck3/game"
+mod = "../my_mod.mod"
+mod = "/mods/patch.mod"
+format = sarif
+rules = {
+	enable = { trait character }
+	disable = { "character/duplicate" }
+	severity = {
+		warning = { "trait/out-of-range" }
+		critical = { parse }
+	}
+}
+ignore = { "common/traits/00_legacy.txt" "history/*" }
+`,
+			want: &Config{
+				GameDir:        "/games/ck3/game",
+				ModDescriptors: []string{filepath.Join(dir, "..", "my_mod.mod"), "/mods/patch.mod"},
+				Format:         "sarif",
+				Enable:         []string{"trait", "character"},
+				Disable:        []string{"character/duplicate"},
+				Severities: []SeverityOverride{
+					{"trait/out-of-range", severity.Warning},
+					{"parse", severity.Critical},
+				},
+				Ignore: []string{"common/traits/00_legacy.txt", "history/*"},
+			},
+		},
+		{
+			name: "empty lists",
+			text: "rules = { enable = { } }\nignore = {}\n",
+			want: &Config{},
+		},
+		{
+			name:  "unknown fields",
+			text:  "games = \"/games\"\nrules = { enabled = { trait } }\n",
+			want:  &Config{},
+			rules: []rules.ID{rules.ConfigUnknownField, rules.ConfigUnknownField},
+		},
+		{
+			name:  "invalid values",
+			text:  "game = { a }\nrules = { disable = { trait \"trait/nope\" } severity = { fatal = { trait } } }\nignore = yes\n",
+			want:  &Config{Disable: []string{"trait"}},
+			rules: []rules.ID{rules.ConfigInvalidValue, rules.ConfigInvalidValue, rules.ConfigInvalidValue, rules.ConfigInvalidValue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, dir, FileName, tt.text)
+
+			got, diagnostics, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.want.Path = path
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+
+			var gotRules []rules.ID
+			for _, item := range diagnostics {
+				gotRules = append(gotRules, item.Rule)
+			}
+			if !reflect.DeepEqual(gotRules, tt.rules) {
+				t.Errorf("Load() reported %v, want %v", gotRules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	if _, _, err := Load(filepath.Join(t.TempDir(), FileName)); err == nil {
+		t.Errorf("Load() of a missing file succeeded")
+	}
+}
+
+func TestFind(t *testing.T) {
+	empty, mod, other := t.TempDir(), t.TempDir(), t.TempDir()
+	want := writeFile(t, mod, FileName, "")
+	writeFile(t, other, FileName, "")
+	if err := os.Mkdir(filepath.Join(empty, FileName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		folders []string
+		want    string
+		found   bool
+	}{
+		{"first folder with a config", []string{empty, mod, other}, want, true},
+		{"no folder", nil, "", false},
+		{"no config", []string{empty}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := Find(tt.folders...)
+			if got != tt.want || found != tt.found {
+				t.Errorf("Find() = %q, %v, want %q, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	mod, game := t.TempDir(), t.TempDir()
+	outOfRange := rules.Scoped(rules.ScopeTrait, rules.CheckOutOfRange)
+
+	// The locations of the files, by root and path relative to it
+	locs := map[string]tokens.Loc{}
+	for _, file := range [][2]string{
+		{mod, "common/traits/t.txt"},
+		{mod, "history/characters/c.txt"},
+		{game, "common/traits/legacy.txt"},
+	} {
+		name := "mod/" + file[1]
+		if file[0] == game {
+			name = "game/" + file[1]
+		}
+		fullpath := writeFile(t, file[0], file[1], "a = b\n")
+		entry, err := files.OpenFileEntry(nil, nil, fullpath, files.Mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locs[name] = *tokens.LocFromFileEntry(entry)
+	}
+
+	type diagnostic struct {
+		path     string
+		rule     rules.ID
+		severity severity.Severity
+	}
+
+	all := []diagnostic{
+		{"mod/common/traits/t.txt", outOfRange, severity.Error},
+		{"mod/common/traits/t.txt", rules.TraitDuplicate, severity.Warning},
+		{"mod/history/characters/c.txt", rules.CharacterDuplicate, severity.Warning},
+		{"game/common/traits/legacy.txt", rules.ParseMissingValue, severity.Error},
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		want   []diagnostic
+	}{
+		{
+			name:   "no settings",
+			config: Config{},
+			want:   all,
+		},
+		{
+			name:   "enable",
+			config: Config{Enable: []string{"trait", "parse/missing-value"}},
+			want:   []diagnostic{all[0], all[1], all[3]},
+		},
+		{
+			name:   "enable and disable",
+			config: Config{Enable: []string{"trait/*"}, Disable: []string{"trait/duplicate"}},
+			want:   []diagnostic{all[0]},
+		},
+		{
+			name: "severity",
+			config: Config{Severities: []SeverityOverride{
+				{"trait", severity.Info},
+				{"trait/duplicate", severity.Critical},
+			}},
+			want: []diagnostic{
+				{"mod/common/traits/t.txt", outOfRange, severity.Info},
+				{"mod/common/traits/t.txt", rules.TraitDuplicate, severity.Critical},
+				all[2], all[3],
+			},
+		},
+		{
+			name:   "ignore folders",
+			config: Config{Ignore: []string{"history/"}},
+			want:   []diagnostic{all[0], all[1], all[3]},
+		},
+		{
+			name:   "ignore patterns",
+			config: Config{Ignore: []string{"common/*/legacy.txt", "common/traits/t.*"}},
+			want:   []diagnostic{all[2]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []*report.DiagnosticItem
+			for _, d := range all {
+				item := report.FromLoc(locs[d.path], d.rule, "problem")
+				item.Severity = d.severity
+				items = append(items, item)
+			}
+
+			var got []diagnostic
+			for _, item := range tt.config.Apply(items, mod, game) {
+				path := ""
+				for p, loc := range locs {
+					if loc.SameFile(item.Pointer.Loc) {
+						path = p
+					}
+				}
+				got = append(got, diagnostic{path, item.Rule, item.Severity})
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,11 @@ package data
 
 import (
 	"log"
-	"path/filepath"
-	"strings"
 
 	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/pkg/entity"
+	"github.com/unLomTrois/gock3/pkg/report"
 )
 
 type Common struct {
@@ -19,23 +19,17 @@ func NewCommon() *Common {
 	}
 }
 
-// Folder returns the folder path for common, using the correct
-// path separator for the operating system.
-// On Windows, it returns "game\\common", and on Linux, "game/common".
+// Folder returns the folder path for common, relative to the game or mod root.
+// It uses forward slashes on every system, as FileEntry.Path does.
 func (c *Common) Folder() string {
-	return filepath.Join("game", "common")
+	return "common"
 }
 
 func (common *Common) Load(fset *files.FileSet) []entity.Entity {
 	var files []*files.FileEntry
 
 	for _, fileEntry := range fset.Files {
-		fullpath := fileEntry.FullPath()
-		if strings.Contains(fullpath, common.Folder()) {
-			files = append(files, fileEntry)
-		}
-
-		if strings.Contains(fullpath, filepath.Clean(fset.ModLoader.Root)) {
+		if fileEntry.InFolder(common.Folder()) {
 			files = append(files, fileEntry)
 		}
 	}
@@ -53,3 +47,13 @@ func (common *Common) Load(fset *files.FileSet) []entity.Entity {
 
 	return entities
 }
+
+// Diagnostics returns the problems found while loading the common folder
+func (common *Common) Diagnostics() []*report.DiagnosticItem {
+	return common.Traits.Diagnostics()
+}
+
+// Directives returns the directives in the comments of the files loaded from the common folder
+func (common *Common) Directives() []*ast.Directive {
+	return common.Traits.Directives()
+}
@@ -2,11 +2,11 @@ package data
 
 import (
 	"log"
-	"path/filepath"
-	"strings"
 
 	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/pkg/entity"
+	"github.com/unLomTrois/gock3/pkg/report"
 )
 
 type History struct {
@@ -19,22 +19,17 @@ func NewHistory() *History {
 	}
 }
 
-// Folder returns the folder path for common, using the correct
-// path separator for the operating system.
+// Folder returns the folder path for history, relative to the game or mod root.
+// It uses forward slashes on every system, as FileEntry.Path does.
 func (c *History) Folder() string {
-	return filepath.Join("game", "history")
+	return "history"
 }
 
 func (history *History) Load(fset *files.FileSet) []entity.Entity {
 	var files []*files.FileEntry
 
 	for _, fileEntry := range fset.Files {
-		fullpath := fileEntry.FullPath()
-		if strings.Contains(fullpath, history.Folder()) {
-			files = append(files, fileEntry)
-		}
-
-		if strings.Contains(fullpath, filepath.Clean(fset.ModLoader.Root)) {
+		if fileEntry.InFolder(history.Folder()) {
 			files = append(files, fileEntry)
 		}
 	}
@@ -50,3 +45,13 @@ func (history *History) Load(fset *files.FileSet) []entity.Entity {
 
 	return entities
 }
+
+// Diagnostics returns the problems found while loading the history folder
+func (history *History) Diagnostics() []*report.DiagnosticItem {
+	return history.Characters.Diagnostics()
+}
+
+// Directives returns the directives in the comments of the files loaded from the history folder
+func (history *History) Directives() []*ast.Directive {
+	return history.Characters.Directives()
+}
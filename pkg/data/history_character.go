@@ -1,10 +1,15 @@
 package data
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/pkg/date"
 	"github.com/unLomTrois/gock3/pkg/entity"
 	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 	"github.com/unLomTrois/gock3/pkg/validator"
 )
 
@@ -26,6 +31,10 @@ func (character *HistoryCharacter) Name() string {
 	return character.name
 }
 
+func (character *HistoryCharacter) Key() *tokens.Token {
+	return character.key
+}
+
 func (character *HistoryCharacter) Location() string {
 	fullpath, err := character.key.Loc.Fullpath()
 
@@ -40,10 +49,51 @@ func (character *HistoryCharacter) GetKind() entity.EntityKind {
 	return entity.KindCharacter
 }
 
+// HistoryEntry is a dated block of a character's history, such as `1066.9.15 = { ... }`
+type HistoryEntry struct {
+	Date  date.Date
+	Key   *tokens.Token
+	Block *ast.FieldBlock
+}
+
+// History returns the dated entries of the character in chronological order.
+// Entries with the same date keep their order in the file.
+// Keys that are not valid dates are left out, since the parser reports them.
+// Entries that also hold bare values keep their fields.
+func (character *HistoryCharacter) History() []*HistoryEntry {
+	var entries []*HistoryEntry
+
+	for _, field := range character.block.Values {
+		d, ok := field.DateKey()
+		if !ok {
+			continue
+		}
+
+		var block *ast.FieldBlock
+		switch value := field.Value.(type) {
+		case *ast.FieldBlock:
+			block = value
+		case *ast.MixedBlock:
+			// An entry with a bare value, such as `1066.1.1 = { birth = yes oops }`, still has its fields
+			block = &value.FieldBlock
+		default:
+			continue
+		}
+
+		entries = append(entries, &HistoryEntry{Date: d, Key: field.Key, Block: block})
+	}
+
+	slices.SortStableFunc(entries, func(a, b *HistoryEntry) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return entries
+}
+
 // var categorySet = mapset.NewSet("personality", "education", "childhood", "commander", "winter_commander", "lifestyle", "court_type", "fame", "health")
 
 func (character *HistoryCharacter) Validate() []*report.DiagnosticItem {
-	fields := validator.NewBlockValidator(character.block)
+	fields := validator.NewBlockValidator(character.block, rules.ScopeCharacter)
 
 	// for key, field := range fields.Fields() {
 	// 	if key == "trait" {
@@ -87,7 +137,28 @@ func (character *HistoryCharacter) Validate() []*report.DiagnosticItem {
 	// fields.ExpectBool("can_have_children")
 	// fields.ExpectBool("enables_inbred")
 
+	character.validateLifespan(fields)
+
 	return fields.Errors()
 }
 
+// validateLifespan reports a character who dies before being born
+func (character *HistoryCharacter) validateLifespan(fields *validator.BlockValidator) {
+	var death *HistoryEntry
+
+	for _, entry := range character.History() {
+		if death == nil && entry.Block.GetField("death") != nil {
+			death = entry
+		}
+
+		if entry.Block.GetField("birth") != nil {
+			if death != nil && death.Date.Before(entry.Date) {
+				msg := fmt.Sprintf("%s dies on %s, before being born on %s", character.name, death.Date, entry.Date)
+				fields.AddError(report.FromToken(death.Key, rules.CharacterDeathOrder, msg))
+			}
+			return
+		}
+	}
+}
+
 // todo: idea, we can make a map of keys and functions that shall validate this key to improve O from O(n) to O(1)
@@ -0,0 +1,87 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer"
+	"github.com/unLomTrois/gock3/internal/app/parser"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+)
+
+// parseCharacters parses history characters from text of an in-memory file
+func parseCharacters(t *testing.T, text string) []*HistoryCharacter {
+	t.Helper()
+
+	fsys := fstest.MapFS{"characters.txt": {Data: []byte(text)}}
+	entry, err := files.OpenFileEntry(nil, fsys, "characters.txt", files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, _ := lexer.Scan(entry, []byte(text))
+	block, _ := parser.Parse(stream)
+
+	var characters []*HistoryCharacter
+	for _, field := range block.Values {
+		if value, ok := field.Value.(*ast.FieldBlock); ok {
+			characters = append(characters, NewHistoryCharacter(field.Key, value))
+		}
+	}
+	return characters
+}
+
+func TestHistoryCharacter_History(t *testing.T) {
+	characters := parseCharacters(t, `
+character = {
+	name = Test
+	1065.12.17 = { effect = { give_nickname = nick_the_strong } }
+	1039.1.1 = { birth = yes }
+	1065.12.17 = { add_pressed_claim = title:k_leon }
+	867.13.40 = { invalid = yes }
+	1072.10.7 = { death = yes }
+	776.1. = { note = yes }
+}`)
+
+	var got []string
+	for _, entry := range characters[0].History() {
+		got = append(got, entry.Date.String()+" "+entry.Block.Values[0].Key.Value)
+	}
+
+	want := []string{
+		"776.1.1 note",
+		"1039.1.1 birth",
+		"1065.12.17 effect",
+		"1065.12.17 add_pressed_claim",
+		"1072.10.7 death",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("History() = %q, want %q", got, want)
+	}
+}
+
+func TestHistoryCharacter_ValidateLifespan(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		errors int
+	}{
+		{"born before dying", "c = { 1039.1.1 = { birth = yes } 1072.10.7 = { death = yes } }", 0},
+		{"born and dead on the same day", "c = { 1039.1.1 = { birth = yes death = yes } }", 0},
+		{"entries out of order in the file", "c = { 1072.10.7 = { death = yes } 1039.1.1 = { birth = yes } }", 0},
+		{"dead before being born", "c = { 1039.1.1 = { birth = yes } 1000.1.1 = { death = yes } }", 1},
+		{"dead before being born in the same year", "c = { 1039.1.1 = { death = yes } 1039.2.1 = { birth = yes } }", 1},
+		{"dead before being born with bare values", "c = { 1039.1.1 = { birth = yes stray } 1000.1.1 = { \"note\" death = yes } }", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			characters := parseCharacters(t, tt.text)
+			if got := len(characters[0].Validate()); got != tt.errors {
+				t.Errorf("Validate() returned %d diagnostics, want %d", got, tt.errors)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package data
 
 import (
 	"log"
-	"path/filepath"
+	"path"
 	"strings"
 
 	"github.com/unLomTrois/gock3/internal/app/files"
@@ -12,8 +12,10 @@ import (
 )
 
 type HistoryCharacters struct {
-	Characters []*HistoryCharacter
-	ast        *ast.AST
+	Characters  []*HistoryCharacter
+	ast         *ast.AST
+	diagnostics []*report.DiagnosticItem
+	directives  []*ast.Directive
 }
 
 func NewHistoryCharacters() *HistoryCharacters {
@@ -23,10 +25,10 @@ func NewHistoryCharacters() *HistoryCharacters {
 	}
 }
 
-// Folder returns the folder path for traits, using the correct
-// path separator for the operating system.
+// Folder returns the folder path for characters, relative to the game or mod root.
+// It uses forward slashes on every system, as FileEntry.Path does.
 func (t *HistoryCharacters) Folder() string {
-	return filepath.Join("history", "characters")
+	return path.Join("history", "characters")
 }
 
 func (hc *HistoryCharacters) Load(fileEntries []*files.FileEntry) []*HistoryCharacter {
@@ -34,8 +36,6 @@ func (hc *HistoryCharacters) Load(fileEntries []*files.FileEntry) []*HistoryChar
 
 	log.Printf("Found %d character files", len(files))
 
-	var problems []*report.DiagnosticItem
-
 	for _, file := range files {
 		ast := hc.parseFile(file)
 		if ast == nil {
@@ -44,11 +44,11 @@ func (hc *HistoryCharacters) Load(fileEntries []*files.FileEntry) []*HistoryChar
 
 		entries, diagnostics := hc.parse(ast.Block)
 		hc.Characters = append(hc.Characters, entries...)
-		problems = append(problems, diagnostics...)
+		hc.diagnostics = append(hc.diagnostics, diagnostics...)
 	}
 
 	log.Printf("Found %d characters", len(hc.Characters))
-	log.Printf("%d problems", len(problems))
+	log.Printf("%d problems", len(hc.diagnostics))
 
 	return hc.Characters
 }
@@ -56,7 +56,7 @@ func (hc *HistoryCharacters) Load(fileEntries []*files.FileEntry) []*HistoryChar
 func (hc *HistoryCharacters) filterFiles(fileEntries []*files.FileEntry) []*files.FileEntry {
 	traitFiles := make([]*files.FileEntry, 0, len(fileEntries))
 	for _, fileEntry := range fileEntries {
-		if strings.Contains(fileEntry.FullPath(), hc.Folder()) {
+		if fileEntry.InFolder(hc.Folder()) {
 			traitFiles = append(traitFiles, fileEntry)
 		}
 	}
@@ -64,14 +64,26 @@ func (hc *HistoryCharacters) filterFiles(fileEntries []*files.FileEntry) []*file
 }
 
 func (hc *HistoryCharacters) parseFile(fileEntry *files.FileEntry) *ast.AST {
-	ast, err := pdxfile.ParseFile(fileEntry)
+	ast, diagnostics, err := pdxfile.ParseFile(fileEntry)
 	if err != nil {
 		log.Printf("Failed to parse file %s: %v", fileEntry.FullPath(), err)
 		return nil
 	}
+	hc.diagnostics = append(hc.diagnostics, diagnostics...)
+	hc.directives = append(hc.directives, ast.Directives...)
 	return ast
 }
 
+// Diagnostics returns the problems found while loading the characters, including syntax errors
+func (hc *HistoryCharacters) Diagnostics() []*report.DiagnosticItem {
+	return hc.diagnostics
+}
+
+// Directives returns the directives in the comments of the character files
+func (hc *HistoryCharacters) Directives() []*ast.Directive {
+	return hc.directives
+}
+
 func (traits *HistoryCharacters) parse(block *ast.FieldBlock) ([]*HistoryCharacter, []*report.DiagnosticItem) {
 	var entities []*HistoryCharacter
 	var problems []*report.DiagnosticItem
@@ -89,7 +101,9 @@ func (traits *HistoryCharacters) parse(block *ast.FieldBlock) ([]*HistoryCharact
 		}
 
 		character := NewHistoryCharacter(key, block)
-		problems = append(problems, character.Validate()...)
+		for _, problem := range character.Validate() {
+			problems = append(problems, problem.WithEntity(key.Value))
+		}
 		entities = append(entities, character)
 	}
 
@@ -6,6 +6,7 @@ import (
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/pkg/entity"
 	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 	"github.com/unLomTrois/gock3/pkg/validator"
 )
 
@@ -27,6 +28,10 @@ func (trait *Trait) Name() string {
 	return trait.name
 }
 
+func (trait *Trait) Key() *tokens.Token {
+	return trait.key
+}
+
 func (trait *Trait) Location() string {
 	fullpath, err := trait.key.Loc.Fullpath()
 
@@ -44,7 +49,7 @@ func (trait *Trait) GetKind() entity.EntityKind {
 var categorySet = mapset.NewSet("personality", "education", "childhood", "commander", "winter_commander", "lifestyle", "court_type", "fame", "health")
 
 func (trait *Trait) Validate() []*report.DiagnosticItem {
-	fields := validator.NewBlockValidator(trait.block)
+	fields := validator.NewBlockValidator(trait.block, rules.ScopeTrait)
 
 	// for _, field := range trait.block.Values {
 	// 	ok := availableKeys.Contains(field.Key.Value)
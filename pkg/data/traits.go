@@ -2,7 +2,7 @@ package data
 
 import (
 	"log"
-	"path/filepath"
+	"path"
 	"strings"
 
 	"github.com/unLomTrois/gock3/internal/app/files"
@@ -12,8 +12,10 @@ import (
 )
 
 type Traits struct {
-	Traits []*Trait
-	ast    *ast.AST
+	Traits      []*Trait
+	ast         *ast.AST
+	diagnostics []*report.DiagnosticItem
+	directives  []*ast.Directive
 }
 
 func NewTraits() *Traits {
@@ -23,10 +25,10 @@ func NewTraits() *Traits {
 	}
 }
 
-// Folder returns the folder path for traits, using the correct
-// path separator for the operating system.
+// Folder returns the folder path for traits, relative to the game or mod root.
+// It uses forward slashes on every system, as FileEntry.Path does.
 func (t *Traits) Folder() string {
-	return filepath.Join("common", "traits")
+	return path.Join("common", "traits")
 }
 
 func (traits *Traits) Load(fileEntries []*files.FileEntry) []*Trait {
@@ -34,8 +36,6 @@ func (traits *Traits) Load(fileEntries []*files.FileEntry) []*Trait {
 
 	log.Printf("Found %d trait files", len(traitFiles))
 
-	var problems []*report.DiagnosticItem
-
 	for _, file := range traitFiles {
 		ast := traits.parseFile(file)
 		if ast == nil {
@@ -44,11 +44,11 @@ func (traits *Traits) Load(fileEntries []*files.FileEntry) []*Trait {
 
 		traitEntries, diagnostics := traits.parseTraits(ast.Block)
 		traits.Traits = append(traits.Traits, traitEntries...)
-		problems = append(problems, diagnostics...)
+		traits.diagnostics = append(traits.diagnostics, diagnostics...)
 	}
 
 	log.Printf("Found %d traits", len(traits.Traits))
-	log.Printf("%d problems", len(problems))
+	log.Printf("%d problems", len(traits.diagnostics))
 
 	return traits.Traits
 }
@@ -56,7 +56,7 @@ func (traits *Traits) Load(fileEntries []*files.FileEntry) []*Trait {
 func (traits *Traits) filterTraitFiles(fileEntries []*files.FileEntry) []*files.FileEntry {
 	traitFiles := make([]*files.FileEntry, 0, len(fileEntries))
 	for _, fileEntry := range fileEntries {
-		if strings.Contains(fileEntry.FullPath(), traits.Folder()) {
+		if fileEntry.InFolder(traits.Folder()) {
 			traitFiles = append(traitFiles, fileEntry)
 		}
 	}
@@ -64,14 +64,26 @@ func (traits *Traits) filterTraitFiles(fileEntries []*files.FileEntry) []*files.
 }
 
 func (traits *Traits) parseFile(fileEntry *files.FileEntry) *ast.AST {
-	ast, err := pdxfile.ParseFile(fileEntry)
+	ast, diagnostics, err := pdxfile.ParseFile(fileEntry)
 	if err != nil {
 		log.Printf("Failed to parse file %s: %v", fileEntry.FullPath(), err)
 		return nil
 	}
+	traits.diagnostics = append(traits.diagnostics, diagnostics...)
+	traits.directives = append(traits.directives, ast.Directives...)
 	return ast
 }
 
+// Diagnostics returns the problems found while loading the traits, including syntax errors
+func (traits *Traits) Diagnostics() []*report.DiagnosticItem {
+	return traits.diagnostics
+}
+
+// Directives returns the directives in the comments of the trait files
+func (traits *Traits) Directives() []*ast.Directive {
+	return traits.directives
+}
+
 func (traits *Traits) parseTraits(block *ast.FieldBlock) ([]*Trait, []*report.DiagnosticItem) {
 	var traitEntries []*Trait
 	var problems []*report.DiagnosticItem
@@ -89,7 +101,9 @@ func (traits *Traits) parseTraits(block *ast.FieldBlock) ([]*Trait, []*report.Di
 		}
 
 		trait := NewTraitFromAST(key, block)
-		problems = append(problems, trait.Validate()...)
+		for _, problem := range trait.Validate() {
+			problems = append(problems, problem.WithEntity(key.Value))
+		}
 		traitEntries = append(traitEntries, trait)
 	}
 
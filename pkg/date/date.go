@@ -0,0 +1,127 @@
+// Package date implements the calendar of the game.
+//
+// Dates are written as year.month.day, such as 1066.9.15. The calendar has 365 days in every year:
+// there are no leap years, so February always has 28 days.
+package date
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// Error describes a date that couldn't be parsed or doesn't exist in the calendar
+type Error struct {
+	Text   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid date %q: %s", e.Text, e.Reason)
+}
+
+var daysInMonth = [12]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// DaysInMonth returns the number of days in the month, or 0 if there is no such month
+func DaysInMonth(month int) int {
+	if month < 1 || month > 12 {
+		return 0
+	}
+	return daysInMonth[month-1]
+}
+
+// New creates a date and checks that it exists in the calendar
+func New(year int, month int, day int) (Date, error) {
+	d := Date{Year: year, Month: month, Day: day}
+	return d, d.Validate()
+}
+
+// Parse reads a date written as year.month.day. The day may be left out, as in `776.1.`,
+// in which case it's the first day of the month.
+func Parse(text string) (Date, error) {
+	parts := strings.Split(text, ".")
+	if len(parts) != 3 {
+		return Date{}, &Error{Text: text, Reason: "expected year.month.day"}
+	}
+
+	year, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Date{}, &Error{Text: text, Reason: "the year is not a number"}
+	}
+
+	month, err := strconv.Atoi(parts[1])
+	if err != nil || strings.HasPrefix(parts[1], "-") || strings.HasPrefix(parts[1], "+") {
+		return Date{}, &Error{Text: text, Reason: "the month is not a number"}
+	}
+
+	day := 1
+	if parts[2] != "" {
+		day, err = strconv.Atoi(parts[2])
+		if err != nil || strings.HasPrefix(parts[2], "-") || strings.HasPrefix(parts[2], "+") {
+			return Date{}, &Error{Text: text, Reason: "the day is not a number"}
+		}
+	}
+
+	d, err := New(year, month, day)
+	if err != nil {
+		return Date{}, &Error{Text: text, Reason: err.Error()}
+	}
+	return d, nil
+}
+
+// Validate checks that the month and the day exist in the calendar
+func (d Date) Validate() error {
+	if d.Month < 1 || d.Month > 12 {
+		return fmt.Errorf("month %d is not between 1 and 12", d.Month)
+	}
+
+	if days := DaysInMonth(d.Month); d.Day < 1 || d.Day > days {
+		return fmt.Errorf("day %d is not between 1 and %d", d.Day, days)
+	}
+
+	return nil
+}
+
+// Compare returns -1 if d is before other, 1 if it's after, and 0 if they are the same day
+func (d Date) Compare(other Date) int {
+	switch {
+	case d.Year != other.Year:
+		return compareInts(d.Year, other.Year)
+	case d.Month != other.Month:
+		return compareInts(d.Month, other.Month)
+	default:
+		return compareInts(d.Day, other.Day)
+	}
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Before reports whether d is before other
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// After reports whether d is after other
+func (d Date) After(other Date) bool {
+	return d.Compare(other) > 0
+}
+
+// String formats the date the way scripts write it, as in 1066.9.15
+func (d Date) String() string {
+	return fmt.Sprintf("%d.%d.%d", d.Year, d.Month, d.Day)
+}
@@ -0,0 +1,96 @@
+package date
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Date
+		wantErr string
+	}{
+		{text: "1066.9.15", want: Date{1066, 9, 15}},
+		{text: "205.1.1", want: Date{205, 1, 1}},
+		{text: "776.1.", want: Date{776, 1, 1}},
+		{text: "-50.12.31", want: Date{-50, 12, 31}},
+		{text: "0001.02.28", want: Date{1, 2, 28}},
+		{text: "1000.2.29", wantErr: "day 29 is not between 1 and 28"},
+		{text: "867.13.40", wantErr: "month 13 is not between 1 and 12"},
+		{text: "867.0.1", wantErr: "month 0 is not between 1 and 12"},
+		{text: "867.4.31", wantErr: "day 31 is not between 1 and 30"},
+		{text: "867.4.0", wantErr: "day 0 is not between 1 and 30"},
+		{text: "867.4", wantErr: "expected year.month.day"},
+		{text: "867.-4.1", wantErr: "the month is not a number"},
+		{text: "year.1.1", wantErr: "the year is not a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Parse(%q) error = %v, want %q", tt.text, err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaysInMonth(t *testing.T) {
+	total := 0
+	for month := 1; month <= 12; month++ {
+		total += DaysInMonth(month)
+	}
+	if total != 365 {
+		t.Errorf("the months have %d days in total, want 365", total)
+	}
+
+	if DaysInMonth(0) != 0 || DaysInMonth(13) != 0 {
+		t.Errorf("DaysInMonth should be 0 for months that don't exist")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b Date
+		want int
+	}{
+		{Date{1066, 9, 15}, Date{1066, 9, 15}, 0},
+		{Date{1066, 9, 15}, Date{1066, 9, 16}, -1},
+		{Date{1066, 10, 1}, Date{1066, 9, 30}, 1},
+		{Date{-1, 12, 31}, Date{1, 1, 1}, -1},
+		{Date{1067, 1, 1}, Date{1066, 12, 31}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%v.Compare(%v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := tt.a.Before(tt.b); got != (tt.want < 0) {
+			t.Errorf("%v.Before(%v) = %v", tt.a, tt.b, got)
+		}
+		if got := tt.a.After(tt.b); got != (tt.want > 0) {
+			t.Errorf("%v.After(%v) = %v", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	d, err := Parse("0867.01.05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.String(); got != "867.1.5" {
+		t.Errorf("String() = %q, want %q", got, "867.1.5")
+	}
+}
@@ -1,7 +1,11 @@
 package entity
 
+import "github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+
 type Entity interface {
 	Name() string
 	Location() string
 	GetKind() EntityKind
+	// Key is the token that defines the entity, such as the name of a trait before its block
+	Key() *tokens.Token
 }
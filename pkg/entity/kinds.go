@@ -6,3 +6,14 @@ const (
 	KindTrait EntityKind = iota
 	KindCharacter
 )
+
+func (k EntityKind) String() string {
+	switch k {
+	case KindTrait:
+		return "trait"
+	case KindCharacter:
+		return "character"
+	default:
+		return "entity"
+	}
+}
@@ -1,10 +1,14 @@
 package project
 
 import (
+	"fmt"
+
 	"github.com/unLomTrois/gock3/internal/app/files"
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/internal/app/pdxfile"
 	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 	"github.com/unLomTrois/gock3/pkg/validator"
 )
 
@@ -18,6 +22,8 @@ type ModFile struct {
 	ReplacePaths     []*tokens.Token `json:"replace_paths"`
 	SupportedVersion *tokens.Token   `json:"supported_version"`
 	Picture          *tokens.Token   `json:"picture"`
+	// Dependencies are the names of the mods that load before this one
+	Dependencies []*tokens.Token `json:"dependencies"`
 }
 
 func NewModFile(AST *ast.AST, file_entry *files.FileEntry) *ModFile {
@@ -33,13 +39,50 @@ func NewModFile(AST *ast.AST, file_entry *files.FileEntry) *ModFile {
 		ReplacePaths:     block.GetFieldsValues("replace_path"),
 		SupportedVersion: block.GetFieldValue("supported_version"),
 		Picture:          block.GetFieldValue("picture"),
+		Dependencies:     block.GetFieldList("dependencies"),
 	}
 }
 
+// ReadModPath returns the path of the mod that a descriptor on the disk points at, without validating the descriptor
+func ReadModPath(descriptor string) (string, error) {
+	entry, err := files.OpenFileEntry(nil, nil, descriptor, files.Mod)
+	if err != nil {
+		return "", err
+	}
+
+	tree, _, err := pdxfile.ParseFile(entry)
+	if err != nil {
+		return "", err
+	}
+
+	path := tree.Block.GetFieldValue("path")
+	if path == nil {
+		return "", fmt.Errorf("mod descriptor %s has no path", descriptor)
+	}
+	return path.Value, nil
+}
+
+// DisplayName returns the name of the mod, or the name of its descriptor if it has none
+func (m *ModFile) DisplayName() string {
+	if m.Name != nil && m.Name.Value != "" {
+		return m.Name.Value
+	}
+	return m.file.FileName()
+}
+
+// ReplacePathValues returns the folders of the replace_path entries
+func (m *ModFile) ReplacePathValues() []string {
+	replacePaths := make([]string, len(m.ReplacePaths))
+	for i, token := range m.ReplacePaths {
+		replacePaths[i] = token.Value
+	}
+	return replacePaths
+}
+
 func (m *ModFile) Validate() []*report.DiagnosticItem {
 	diagnostics := make([]*report.DiagnosticItem, 0)
 
-	fields := validator.NewBlockValidator(m.AST.Block)
+	fields := validator.NewBlockValidator(m.AST.Block, rules.ScopeMod)
 
 	// Check for required fields
 	fields.RequireField("version")
@@ -57,13 +100,21 @@ func (m *ModFile) Validate() []*report.DiagnosticItem {
 
 	diagnostics = append(diagnostics, fields.Errors()...)
 
-	// validate token block
-	tags := m.AST.Block.GetTokenBlock("tags")
+	// validate token block, tags are optional too
+	if tags := m.AST.Block.GetTokenBlock("tags"); tags != nil {
+		tag_validator := validator.NewTokenValidator(tags, rules.ScopeMod)
+		tag_validator.ExpectAllTokensToBe(tokens.QUOTED_STRING)
+
+		diagnostics = append(diagnostics, tag_validator.Errors()...)
+	}
 
-	tag_validator := validator.NewTokenValidator(tags)
-	tag_validator.ExpectAllTokensToBe(tokens.QUOTED_STRING)
+	// Most mods have no dependencies
+	if dependencies := m.AST.Block.GetTokenBlock("dependencies"); dependencies != nil {
+		dependency_validator := validator.NewTokenValidator(dependencies, rules.ScopeMod)
+		dependency_validator.ExpectAllTokensToBe(tokens.QUOTED_STRING)
 
-	diagnostics = append(diagnostics, tag_validator.Errors()...)
+		diagnostics = append(diagnostics, dependency_validator.Errors()...)
+	}
 
 	return diagnostics
 }
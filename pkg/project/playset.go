@@ -0,0 +1,112 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// dlcLoad is the dlc_load.json of the launcher, next to the mod folder in the documents of the game
+type dlcLoad struct {
+	EnabledMods []string `json:"enabled_mods"`
+}
+
+// ReadDLCLoad returns the descriptors of the mods enabled in a dlc_load.json of the launcher, in its order.
+// Relative paths, such as mod/my_mod.mod, are relative to the folder of the file.
+func ReadDLCLoad(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading the playset: %w", err)
+	}
+
+	var playset dlcLoad
+	if err := json.Unmarshal(content, &playset); err != nil {
+		return nil, fmt.Errorf("parsing the playset %s: %w", path, err)
+	}
+
+	descriptors := make([]string, len(playset.EnabledMods))
+	for i, descriptor := range playset.EnabledMods {
+		if !filepath.IsAbs(descriptor) {
+			descriptor = filepath.Join(filepath.Dir(path), descriptor)
+		}
+		descriptors[i] = descriptor
+	}
+
+	return descriptors, nil
+}
+
+// OrderMods returns the mods in load order: every mod loads after the mods it depends on,
+// and the mods keep the order they are given in otherwise.
+// Dependencies missing from the mods and dependency cycles are reported.
+func OrderMods(mods []*ModFile) ([]*ModFile, []*report.DiagnosticItem) {
+	byName := make(map[string]*ModFile, len(mods))
+	for _, mod := range mods {
+		if _, exists := byName[mod.DisplayName()]; !exists {
+			byName[mod.DisplayName()] = mod
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make(map[*ModFile]int, len(mods))
+	ordered := make([]*ModFile, 0, len(mods))
+	var diagnostics []*report.DiagnosticItem
+
+	// path is the chain of mods being visited, to describe cycles
+	var path []*ModFile
+	var visit func(mod *ModFile)
+	visit = func(mod *ModFile) {
+		state[mod] = visiting
+		path = append(path, mod)
+
+		for _, dependency := range mod.Dependencies {
+			required, ok := byName[dependency.Value]
+			switch {
+			case !ok:
+				diagnostics = append(diagnostics, report.FromToken(dependency, rules.ModMissingDependency,
+					fmt.Sprintf("mod %s depends on %s, which is not in the playset", mod.DisplayName(), dependency.Value)))
+			case state[required] == visiting:
+				diagnostics = append(diagnostics, report.FromToken(dependency, rules.ModDependencyCycle,
+					fmt.Sprintf("mods depend on each other: %s", describeCycle(path, required))))
+			case state[required] == 0:
+				visit(required)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[mod] = done
+		ordered = append(ordered, mod)
+	}
+
+	for _, mod := range mods {
+		if state[mod] == 0 {
+			visit(mod)
+		}
+	}
+
+	return ordered, diagnostics
+}
+
+// describeCycle returns the names of the mods of the cycle that goes back to the mod, such as A -> B -> A
+func describeCycle(path []*ModFile, mod *ModFile) string {
+	var names []string
+	for i := len(path) - 1; i >= 0; i-- {
+		names = append(names, path[i].DisplayName())
+		if path[i] == mod {
+			break
+		}
+	}
+
+	// The path was walked backwards, from the last mod to the first mod of the cycle
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(append(names, mod.DisplayName()), " -> ")
+}
@@ -0,0 +1,124 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// newMod writes a descriptor of a mod with the dependencies and loads it
+func newMod(t *testing.T, name string, dependencies string) *ModFile {
+	t.Helper()
+
+	descriptor := filepath.Join(t.TempDir(), name+".mod")
+	text := "version = \"1.0\"\nname = \"" + name + "\"\npath = \"mod/" + name + "\"\n"
+	if dependencies != "" {
+		text += "dependencies = { " + dependencies + " }\n"
+	}
+	if err := os.WriteFile(descriptor, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Project{}
+	mod := p.LoadMod(descriptor)
+	if mod == nil || len(p.Diagnostics) > 0 {
+		t.Fatalf("LoadMod(%s) = %v, %v", name, mod, p.Diagnostics)
+	}
+	return mod
+}
+
+func TestOrderMods(t *testing.T) {
+	type mod struct {
+		name         string
+		dependencies string
+	}
+
+	tests := []struct {
+		name  string
+		mods  []mod
+		want  []string
+		rules []rules.ID
+	}{
+		{
+			name: "given order",
+			mods: []mod{{"A", ""}, {"B", ""}, {"C", ""}},
+			want: []string{"A", "B", "C"},
+		},
+		{
+			name: "dependencies first",
+			mods: []mod{{"Patch", `"Big Mod" "Base"`}, {"Big Mod", `"Base"`}, {"Other", ""}, {"Base", ""}},
+			want: []string{"Base", "Big Mod", "Patch", "Other"},
+		},
+		{
+			name:  "missing dependency",
+			mods:  []mod{{"Patch", `"Big Mod"`}, {"Other", ""}},
+			want:  []string{"Patch", "Other"},
+			rules: []rules.ID{rules.ModMissingDependency},
+		},
+		{
+			name:  "cycle",
+			mods:  []mod{{"A", `"B"`}, {"B", `"C"`}, {"C", `"A"`}},
+			want:  []string{"C", "B", "A"},
+			rules: []rules.ID{rules.ModDependencyCycle},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mods []*ModFile
+			for _, m := range tt.mods {
+				mods = append(mods, newMod(t, m.name, m.dependencies))
+			}
+
+			ordered, diagnostics := OrderMods(mods)
+
+			var got []string
+			for _, mod := range ordered {
+				got = append(got, mod.DisplayName())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrderMods() = %q, want %q", got, tt.want)
+			}
+
+			var gotRules []rules.ID
+			for _, diagnostic := range diagnostics {
+				gotRules = append(gotRules, diagnostic.Rule)
+			}
+			if !reflect.DeepEqual(gotRules, tt.rules) {
+				t.Errorf("OrderMods() reported %v, want %v", gotRules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestOrderMods_CycleMessage(t *testing.T) {
+	_, diagnostics := OrderMods([]*ModFile{newMod(t, "A", `"B"`), newMod(t, "B", `"A"`)})
+	if len(diagnostics) != 1 {
+		t.Fatalf("OrderMods() reported %v, want a cycle", diagnostics)
+	}
+	if want := "mods depend on each other: A -> B -> A"; diagnostics[0].Msg != want {
+		t.Errorf("message = %q, want %q", diagnostics[0].Msg, want)
+	}
+}
+
+func TestReadDLCLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dlc_load.json")
+	content := `{"enabled_mods": ["mod/ugc_1.mod", "/mods/local.mod"], "disabled_dlcs": []}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadDLCLoad(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(dir, "mod", "ugc_1.mod"), "/mods/local.mod"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDLCLoad() = %q, want %q", got, want)
+	}
+}
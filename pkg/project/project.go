@@ -1,88 +1,208 @@
 package project
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
-	"os"
-	"strings"
+	"path"
+	"path/filepath"
 
-	"github.com/fatih/color"
 	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/internal/app/pdxfile"
-	"github.com/unLomTrois/gock3/pkg/cache"
 	"github.com/unLomTrois/gock3/pkg/data"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/suppress"
 	symboltable "github.com/unLomTrois/gock3/pkg/symbol_table"
 )
 
 type Project struct {
-	VanillaDir        string
-	ModFileDescriptor string
-	Diagnostics       []*report.DiagnosticItem
-	Common            *data.Common
-	History           *data.History
-	SymbolTable       *symboltable.SymbolTable
+	// FS is the file system the game, the descriptors and the mods are read from, the disk if it is nil
+	FS fs.FS
+	// Paths holds the paths of the files of the project, which the locations of its tokens and diagnostics resolve against
+	Paths      *files.PathTable
+	VanillaDir string
+	// ModFileDescriptors are the descriptors of the mods of the playset, in the order they were given
+	ModFileDescriptors []string
+	// Mods are the descriptors in load order of the mods that could be read, known once the project is loaded
+	Mods []*ModFile
+	// ModRoots are the directories of the mods in load order, known once the descriptors are loaded
+	ModRoots []string
+	// Overlay is the content of files to read instead of their saved content, such as the unsaved buffers of an editor,
+	// by their path as shown in diagnostics. Set it before loading the project.
+	Overlay     map[string][]byte
+	Diagnostics []*report.DiagnosticItem
+	Common      *data.Common
+	History     *data.History
+	SymbolTable *symboltable.SymbolTable
 }
 
-func NewProject(vanillaDir string, modFileDescriptor string) (*Project, error) {
+// NewProject creates a project of the game and a playset of mods, read from the disk.
+// The mods load after their dependencies, and in the given order otherwise; a descriptor given twice loads once.
+// The path of a mod can be a folder or a .zip archive of the mod.
+func NewProject(vanillaDir string, modFileDescriptors ...string) (*Project, error) {
+	return NewProjectFS(nil, vanillaDir, modFileDescriptors...)
+}
+
+// NewProjectFS is NewProject with the files read from fsys, where the game directory, the descriptors
+// and the paths of the mods in the descriptors are slash-separated paths
+func NewProjectFS(fsys fs.FS, vanillaDir string, modFileDescriptors ...string) (*Project, error) {
 	// check game dir
-	if _, err := os.Stat(vanillaDir); os.IsNotExist(err) {
+	if _, err := files.Stat(fsys, vanillaDir); errors.Is(err, fs.ErrNotExist) {
 		return nil, fmt.Errorf("game directory %s does not exist", vanillaDir)
 	}
-	// check mod file
-	if _, err := os.Stat(modFileDescriptor); os.IsNotExist(err) {
-		return nil, fmt.Errorf("mod file %s does not exist", modFileDescriptor)
+
+	if len(modFileDescriptors) == 0 {
+		return nil, fmt.Errorf("no mod descriptor given")
+	}
+
+	var descriptors []string
+	seen := make(map[string]bool)
+	for _, modFileDescriptor := range modFileDescriptors {
+		// check mod file
+		if _, err := files.Stat(fsys, modFileDescriptor); errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("mod file %s does not exist", modFileDescriptor)
+		}
+
+		key := modFileDescriptor
+		if fsys != nil {
+			key = path.Clean(modFileDescriptor)
+		} else if absolute, err := filepath.Abs(modFileDescriptor); err == nil {
+			key = absolute
+		}
+		if !seen[key] {
+			seen[key] = true
+			descriptors = append(descriptors, modFileDescriptor)
+		}
 	}
 
 	return &Project{
-		VanillaDir:        vanillaDir,
-		ModFileDescriptor: modFileDescriptor,
-		Diagnostics:       []*report.DiagnosticItem{},
-		Common:            data.NewCommon(),
-		History:           data.NewHistory(),
-		SymbolTable:       symboltable.NewSymbolTable(),
+		FS:                 fsys,
+		Paths:              files.NewPathTable(),
+		VanillaDir:         vanillaDir,
+		ModFileDescriptors: descriptors,
+		Diagnostics:        []*report.DiagnosticItem{},
+		Common:             data.NewCommon(),
+		History:            data.NewHistory(),
+		SymbolTable:        symboltable.NewSymbolTable(),
 	}, nil
 }
 
-func (project *Project) Load() {
-	mod := project.LoadMod()
+// Load loads the playset of the project: the game and the mods of its descriptors, in their load order.
+// The error is for a game or a mod whose files can't be read, such as a broken archive.
+func (project *Project) Load() error {
+	var mods []*ModFile
+	for _, descriptor := range project.ModFileDescriptors {
+		mod := project.LoadMod(descriptor)
+		if mod == nil {
+			continue
+		}
+		// The missing path is reported by the validation of the descriptor
+		if mod.Path == nil {
+			log.Printf("Skipping mod %s, its descriptor has no path", mod.DisplayName())
+			continue
+		}
+		mods = append(mods, mod)
+	}
+
+	mods, orderDiagnostics := OrderMods(mods)
+	project.Diagnostics = append(project.Diagnostics, orderDiagnostics...)
+
+	var modLoaders []*files.ModLoader
+	var loaded []*ModFile
+	for _, mod := range mods {
+		modFS, err := project.openFolder(mod.Path.Value)
+		if err != nil {
+			return fmt.Errorf("opening mod %s: %w", mod.DisplayName(), err)
+		}
 
-	replacePaths := make([]string, len(mod.ReplacePaths))
-	for i, token := range mod.ReplacePaths {
-		replacePaths[i] = token.Value
+		modLoader := files.NewModLoader(mod.DisplayName(), mod.Path.Value, mod.ReplacePathValues())
+		modLoader.FS = modFS
+		modLoaders = append(modLoaders, modLoader)
+		loaded = append(loaded, mod)
+		project.ModRoots = append(project.ModRoots, mod.Path.Value)
 	}
+	project.Mods = loaded
 
-	log.Println("mod path", mod.Path)
+	fset := files.NewFileSet(project.VanillaDir, modLoaders...)
 
-	modLoader := files.NewModLoader(mod.Path.Value, replacePaths)
-	fset := files.NewFileSet(project.VanillaDir, modLoader)
+	gameFS, err := project.openFolder(project.VanillaDir)
+	if err != nil {
+		return fmt.Errorf("opening game folder: %w", err)
+	}
 
-	fileEntries, err := files.Scan(project.VanillaDir, modLoader.Root, replacePaths)
+	fileEntries, err := files.ScanPlaysetFS(project.Paths, gameFS, project.VanillaDir, modLoaders...)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	fset.Files = fileEntries
 
 	commonEntities := project.Common.Load(fset)
-	project.SymbolTable.AddEntities(commonEntities)
+	duplicates := project.SymbolTable.AddEntities(commonEntities)
 	log.Println("symbol table items: ", project.SymbolTable.Len())
 
 	historyEntities := project.History.Load(fset)
-	project.SymbolTable.AddEntities(historyEntities)
+	duplicates = append(duplicates, project.SymbolTable.AddEntities(historyEntities)...)
 	log.Println("symbol table items: ", project.SymbolTable.Len())
 
-	project.Validate()
+	project.Diagnostics = append(project.Diagnostics, project.Common.Diagnostics()...)
+	project.Diagnostics = append(project.Diagnostics, project.History.Diagnostics()...)
+	project.Diagnostics = append(project.Diagnostics, duplicates...)
+
+	// Drop the diagnostics silenced by gock3:ignore comments, and report the comments that silence nothing
+	var directives []*ast.Directive
+	for _, mod := range project.Mods {
+		directives = append(directives, mod.AST.Directives...)
+	}
+	directives = append(directives, project.Common.Directives()...)
+	directives = append(directives, project.History.Directives()...)
+	project.Diagnostics = suppress.Apply(project.Diagnostics, directives)
+	return nil
+}
+
+// openFolder returns the file system of the game or of a mod, with the files of the overlay in it laid over
+func (p *Project) openFolder(root string) (fs.FS, error) {
+	folder, err := files.OpenFolder(p.FS, root)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := make(map[string][]byte)
+	for fullpath, content := range p.Overlay {
+		relative, err := filepath.Rel(root, fullpath)
+		if err != nil || !filepath.IsLocal(relative) {
+			continue
+		}
+		overlay[filepath.ToSlash(relative)] = content
+	}
+	if len(overlay) == 0 {
+		return folder, nil
+	}
+	return files.NewOverlay(folder, overlay), nil
 }
 
-func (p *Project) LoadMod() *ModFile {
-	file_entry := files.NewFileEntry(p.ModFileDescriptor, files.FileKind(files.Mod))
+// LoadMod parses and validates a mod descriptor, and returns nil if it can't be read
+func (p *Project) LoadMod(descriptor string) *ModFile {
+	// The descriptor is read through the overlay too, like the files of the mods
+	folder, err := p.openFolder(filepath.Dir(descriptor))
+	if err != nil {
+		log.Printf("Failed to read mod descriptor %s: %v", descriptor, err)
+		return nil
+	}
+	file_entry, err := files.NewFileEntryFS(p.Paths, folder, filepath.Base(descriptor), descriptor, files.FileKind(files.Mod))
+	if err != nil {
+		log.Printf("Failed to read mod descriptor %s: %v", descriptor, err)
+		return nil
+	}
 
-	AST, err := pdxfile.ParseFile(file_entry)
+	AST, parseDiagnostics, err := pdxfile.ParseFile(file_entry)
 	if err != nil {
+		log.Printf("Failed to parse mod descriptor %s: %v", descriptor, err)
 		return nil
 	}
+	p.Diagnostics = append(p.Diagnostics, parseDiagnostics...)
 
 	mod := NewModFile(AST, file_entry)
 
@@ -92,47 +212,7 @@ func (p *Project) LoadMod() *ModFile {
 	return mod
 }
 
+// Validate returns the problems found while loading the project
 func (p *Project) Validate() []*report.DiagnosticItem {
-	file_cache := cache.NewFileCache()
-
-	if len(p.Diagnostics) > 0 {
-		for _, err := range p.Diagnostics {
-			var c *color.Color
-			switch err.Severity {
-			case severity.Error:
-				c = color.New(color.FgRed)
-			case severity.Warning:
-				c = color.New(color.FgYellow)
-			case severity.Info:
-				c = color.New(color.FgCyan)
-			}
-			filename, _ := err.Pointer.Loc.Filename()
-			column := err.Pointer.Loc.Column
-			line := err.Pointer.Loc.Line
-
-			err_line := getErrorLine(file_cache, err, column)
-
-			if err.Pointer.Loc.Line == 1 && err.Pointer.Loc.Column == 1 {
-				c.Println(fmt.Sprintf("[%s:%d:%d]: %s", filename, line, column, err.Msg))
-
-				continue
-			}
-
-			c.Println(fmt.Sprintf("[%s:%d:%d]: %s, got %s", filename, line, column, err.Msg, err_line))
-		}
-	}
-
 	return p.Diagnostics
 }
-
-func getErrorLine(fileCache *cache.FileCache, err *report.DiagnosticItem, column uint16) string {
-	line_start := fileCache.GetLine(&err.Pointer.Loc)
-	// fmt.Println(strconv.Quote(lineStart))
-
-	// replace tabs to spaces, because loc sees \t as 4 symbols...
-	// todo: do something
-	spaced_line := strings.ReplaceAll(line_start, "\t", "    ")
-
-	errorEndIndex := column + uint16(err.Pointer.Length) - 1
-	return spaced_line[:errorEndIndex]
-}
@@ -0,0 +1,159 @@
+package project
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/pkg/entity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+func TestProject_LoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"game/common/traits/00_traits.txt":      {Data: []byte("brave = {}\ncraven = {}\n")},
+		"game/history/characters/00_chars.txt":  {Data: []byte("")},
+		"documents/mod/my.mod":                  {Data: []byte("version = \"1.0\"\nname = \"My\"\npath = \"mods/my\"\n")},
+		"mods/my/common/traits/00_traits.txt":   {Data: []byte("brave = {}\n")},
+		"mods/my/common/traits/zz_traits.txt":   {Data: []byte("shy = {}\nshy = {}\n")},
+		"mods/my/history/characters/mine.txt":   {Data: []byte("")},
+		"mods/my/history/characters/broken.txt": {Data: []byte("a == { b = c }\n")},
+	}
+
+	tests := []struct {
+		name    string
+		overlay map[string][]byte
+		mod     string
+		traits  []string
+		rules   []rules.ID
+	}{
+		{
+			name:   "saved files",
+			mod:    "My",
+			traits: []string{"brave", "shy"},
+			rules:  []rules.ID{rules.ParseComparisonWithBlock, rules.TraitDuplicate},
+		},
+		{
+			name: "unsaved buffers",
+			overlay: map[string][]byte{
+				filepath.Join("mods", "my", "common", "traits", "zz_traits.txt"):   []byte("shy = {}\nbold = {}\n"),
+				filepath.Join("mods", "my", "history", "characters", "broken.txt"): []byte("a = { b = c }\n"),
+				filepath.Join("documents", "mod", "my.mod"):                        []byte("version = \"1.0\"\nname = \"Unsaved\"\npath = \"mods/my\"\n"),
+			},
+			mod:    "Unsaved",
+			traits: []string{"brave", "shy", "bold"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := NewProjectFS(fsys, "game", "documents/mod/my.mod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			project.Overlay = tt.overlay
+			if err := project.Load(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(project.ModRoots) != 1 || project.ModRoots[0] != "mods/my" {
+				t.Errorf("ModRoots = %q, want the folder of the mod", project.ModRoots)
+			}
+			if len(project.Mods) != 1 || project.Mods[0].DisplayName() != tt.mod {
+				t.Errorf("the mod is not named %s", tt.mod)
+			}
+
+			for _, trait := range tt.traits {
+				if !project.SymbolTable.Contains(entity.KindTrait, trait) {
+					t.Errorf("trait %s is missing", trait)
+				}
+			}
+			// The file of the mod replaces the file of the game
+			if project.SymbolTable.Contains(entity.KindTrait, "craven") {
+				t.Errorf("trait craven of the replaced file is loaded")
+			}
+
+			var got []rules.ID
+			for _, diagnostic := range project.Validate() {
+				got = append(got, diagnostic.Rule)
+				path, _ := diagnostic.Pointer.Loc.Pathname()
+				if !strings.HasPrefix(filepath.ToSlash(path), "mods/my/") {
+					t.Errorf("diagnostic %s has the path %q, want the path of the file in the mod", diagnostic.Rule, path)
+				}
+			}
+			if !slices.Equal(got, tt.rules) {
+				t.Errorf("Validate() = %v, want %v", got, tt.rules)
+			}
+		})
+	}
+}
+
+func TestProject_Load_Errors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"game/common/traits/00_traits.txt": {Data: []byte("brave = {}\n")},
+		"broken.mod":                       {Data: []byte("version = \"1.0\"\nname = \"Broken\"\npath = \"broken.zip\"\n")},
+		"broken.zip":                       {Data: []byte("not a zip")},
+		"my.mod":                           {Data: []byte("version = \"1.0\"\nname = \"My\"\npath = \"mod\"\n")},
+		"mod/common/traits/00_traits.txt":  {Data: []byte("brave = {}\n")},
+	}
+
+	tests := []struct {
+		name        string
+		game        string
+		descriptors []string
+	}{
+		{"broken archive of a mod", "game", []string{"broken.mod"}},
+		{"broken archive of the game", "broken.zip", []string{"my.mod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := NewProjectFS(fsys, tt.game, tt.descriptors...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := project.Load(); err == nil {
+				t.Errorf("Load() succeeded")
+			}
+		})
+	}
+}
+
+func TestProject_Paths(t *testing.T) {
+	fsys := fstest.MapFS{
+		"game/common/traits/00_traits.txt": {Data: []byte("brave = {}\n")},
+		"my.mod":                           {Data: []byte("version = \"1.0\"\nname = \"My\"\npath = \"mod\"\n")},
+		"mod/common/traits/00_traits.txt":  {Data: []byte("brave = {}\nbrave = {}\n")},
+	}
+
+	load := func() *Project {
+		project, err := NewProjectFS(fsys, "game", "my.mod")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := project.Load(); err != nil {
+			t.Fatal(err)
+		}
+		return project
+	}
+	first, second := load(), load()
+
+	if first.Paths == second.Paths {
+		t.Fatalf("the projects share their path table")
+	}
+	// The descriptor and the file of the mod, which replaces the file of the game
+	if first.Paths.Len() != 2 || second.Paths.Len() != 2 {
+		t.Errorf("the path tables have %d and %d paths, want 2", first.Paths.Len(), second.Paths.Len())
+	}
+
+	// Locations resolve against the table of their project
+	for _, project := range []*Project{first, second} {
+		for _, diagnostic := range project.Validate() {
+			if _, err := project.Paths.LookupFullpath(diagnostic.Pointer.Loc.GetIdx()); err != nil {
+				t.Errorf("diagnostic %s doesn't resolve against its project: %v", diagnostic.Rule, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package baseline records the diagnostics of a project, so that later runs report only the new ones.
+//
+// Diagnostics are matched by their fingerprint: the rule, the path of the file relative to its root,
+// the key of the entity and the message. Lines are left out, so that edits elsewhere in a file
+// don't turn old diagnostics into new ones.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report"
+)
+
+// Version is the version of the baseline file format
+const Version = 1
+
+// Fingerprint identifies a diagnostic across runs
+type Fingerprint struct {
+	Rule string `json:"rule"`
+	// Path is relative to the root that contains the file, with forward slashes
+	Path    string `json:"path,omitempty"`
+	Entity  string `json:"entity,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Fingerprint) String() string {
+	var b strings.Builder
+	b.WriteString(f.Rule)
+	if f.Path != "" {
+		b.WriteString(" " + f.Path)
+	}
+	if f.Entity != "" {
+		b.WriteString(" " + f.Entity)
+	}
+	b.WriteString(": " + f.Message)
+	return b.String()
+}
+
+// Entry is a fingerprint and the number of diagnostics that have it
+type Entry struct {
+	Fingerprint
+	Count int `json:"count"`
+}
+
+// Baseline is the set of known diagnostics
+type Baseline struct {
+	Version int      `json:"version"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Fingerprinter computes the fingerprints of diagnostics, with paths relative to roots such as the mod directory
+type Fingerprinter struct {
+	roots *report.Roots
+}
+
+// NewFingerprinter returns a Fingerprinter for the roots. Empty roots are left out.
+func NewFingerprinter(roots ...string) *Fingerprinter {
+	return &Fingerprinter{roots: report.NewRoots(roots...)}
+}
+
+// Fingerprint returns the fingerprint of the diagnostic
+func (f *Fingerprinter) Fingerprint(item *report.DiagnosticItem) Fingerprint {
+	fingerprint := Fingerprint{Rule: string(item.Rule), Entity: item.Entity, Message: item.Msg}
+	if item.Pointer != nil {
+		if path, err := item.Pointer.Loc.Pathname(); err == nil {
+			fingerprint.Path, _ = f.roots.Relative(path)
+		}
+	}
+	return fingerprint
+}
+
+// New returns the baseline of the diagnostics, with entries sorted by fingerprint
+func (f *Fingerprinter) New(items []*report.DiagnosticItem) *Baseline {
+	counts := make(map[Fingerprint]int)
+	for _, item := range items {
+		counts[f.Fingerprint(item)]++
+	}
+
+	baseline := &Baseline{Version: Version, Entries: make([]*Entry, 0, len(counts))}
+	for fingerprint, count := range counts {
+		baseline.Entries = append(baseline.Entries, &Entry{Fingerprint: fingerprint, Count: count})
+	}
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		return less(baseline.Entries[i].Fingerprint, baseline.Entries[j].Fingerprint)
+	})
+
+	return baseline
+}
+
+func less(a, b Fingerprint) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	if a.Rule != b.Rule {
+		return a.Rule < b.Rule
+	}
+	if a.Entity != b.Entity {
+		return a.Entity < b.Entity
+	}
+	return a.Message < b.Message
+}
+
+// Filter returns the diagnostics that are not in the baseline, in their order,
+// and the entries of the baseline that no diagnostic matches any more.
+// An entry matches as many diagnostics as its count; the count of a fixed entry is the number of diagnostics gone.
+func (f *Fingerprinter) Filter(baseline *Baseline, items []*report.DiagnosticItem) ([]*report.DiagnosticItem, []*Entry) {
+	remaining := make(map[Fingerprint]int, len(baseline.Entries))
+	for _, entry := range baseline.Entries {
+		remaining[entry.Fingerprint] += entry.Count
+	}
+
+	var fresh []*report.DiagnosticItem
+	for _, item := range items {
+		fingerprint := f.Fingerprint(item)
+		if remaining[fingerprint] > 0 {
+			remaining[fingerprint]--
+			continue
+		}
+		fresh = append(fresh, item)
+	}
+
+	var fixed []*Entry
+	for _, entry := range baseline.Entries {
+		if count := remaining[entry.Fingerprint]; count > 0 {
+			fixed = append(fixed, &Entry{Fingerprint: entry.Fingerprint, Count: count})
+			// Entries listed twice are reported once
+			remaining[entry.Fingerprint] = 0
+		}
+	}
+
+	return fresh, fixed
+}
+
+// Read reads a baseline file
+func Read(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %w", path, err)
+	}
+	if baseline.Version != Version {
+		return nil, fmt.Errorf("baseline %s has version %d, expected %d", path, baseline.Version, Version)
+	}
+
+	return &baseline, nil
+}
+
+// Write writes the baseline to a file
+func (b *Baseline) Write(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing baseline: %w", err)
+	}
+
+	return nil
+}
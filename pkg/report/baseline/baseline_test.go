@@ -0,0 +1,162 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// newLoc creates a file at the path relative to root and returns its location
+func newLoc(t *testing.T, root string, path string) tokens.Loc {
+	t.Helper()
+
+	fullpath := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(fullpath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullpath, []byte("a = b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := files.OpenFileEntry(nil, nil, fullpath, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *tokens.LocFromFileEntry(entry)
+}
+
+func TestFingerprint(t *testing.T) {
+	mod, game := t.TempDir(), t.TempDir()
+	fingerprinter := NewFingerprinter(mod, "", game)
+
+	loc := newLoc(t, mod, "common/traits/t.txt")
+	loc.Line = 12
+	item := report.FromLoc(loc, rules.TraitDuplicate, "trait brave is defined twice").WithEntity("brave")
+
+	want := Fingerprint{Rule: "trait/duplicate", Path: "common/traits/t.txt", Entity: "brave", Message: "trait brave is defined twice"}
+	if got := fingerprinter.Fingerprint(item); got != want {
+		t.Errorf("Fingerprint() = %+v, want %+v", got, want)
+	}
+
+	// Lines don't matter
+	loc.Line = 40
+	moved := report.FromLoc(loc, rules.TraitDuplicate, "trait brave is defined twice").WithEntity("brave")
+	if got := fingerprinter.Fingerprint(moved); got != want {
+		t.Errorf("Fingerprint() of the moved diagnostic = %+v, want %+v", got, want)
+	}
+
+	outside := newLoc(t, t.TempDir(), "x.txt")
+	path, _ := outside.Pathname()
+	if got := fingerprinter.Fingerprint(report.FromLoc(outside, rules.TraitDuplicate, "")).Path; got != filepath.ToSlash(path) {
+		t.Errorf("Fingerprint() of a file outside the roots has path %q, want %q", got, path)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	root := t.TempDir()
+	fingerprinter := NewFingerprinter(root)
+	a, b := newLoc(t, root, "a.txt"), newLoc(t, root, "b.txt")
+
+	type diagnostic struct {
+		loc    tokens.Loc
+		entity string
+		msg    string
+	}
+
+	tests := []struct {
+		name  string
+		old   []diagnostic
+		new   []diagnostic
+		fresh []string
+		fixed []Entry
+	}{
+		{
+			name:  "unchanged",
+			old:   []diagnostic{{a, "brave", "x"}, {b, "", "y"}},
+			new:   []diagnostic{{b, "", "y"}, {a, "brave", "x"}},
+			fresh: nil,
+			fixed: nil,
+		},
+		{
+			name:  "new diagnostics",
+			old:   []diagnostic{{a, "brave", "x"}},
+			new:   []diagnostic{{a, "brave", "x"}, {a, "craven", "x"}, {b, "brave", "x"}, {a, "brave", "z"}},
+			fresh: []string{"a.txt craven x", "b.txt brave x", "a.txt brave z"},
+			fixed: nil,
+		},
+		{
+			name:  "more of the same",
+			old:   []diagnostic{{a, "brave", "x"}},
+			new:   []diagnostic{{a, "brave", "x"}, {a, "brave", "x"}},
+			fresh: []string{"a.txt brave x"},
+			fixed: nil,
+		},
+		{
+			name:  "fixed",
+			old:   []diagnostic{{a, "brave", "x"}, {a, "brave", "x"}, {b, "", "y"}},
+			new:   []diagnostic{{a, "brave", "x"}},
+			fresh: nil,
+			fixed: []Entry{
+				{Fingerprint{Rule: "trait/duplicate", Path: "a.txt", Entity: "brave", Message: "x"}, 1},
+				{Fingerprint{Rule: "trait/duplicate", Path: "b.txt", Message: "y"}, 1},
+			},
+		},
+	}
+
+	items := func(diagnostics []diagnostic) []*report.DiagnosticItem {
+		var result []*report.DiagnosticItem
+		for _, d := range diagnostics {
+			result = append(result, report.FromLoc(d.loc, rules.TraitDuplicate, d.msg).WithEntity(d.entity))
+		}
+		return result
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Go through a file, as the commands do
+			path := filepath.Join(t.TempDir(), "baseline.json")
+			if err := fingerprinter.New(items(tt.old)).Write(path); err != nil {
+				t.Fatal(err)
+			}
+			known, err := Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fresh, fixed := fingerprinter.Filter(known, items(tt.new))
+
+			var got []string
+			for _, item := range fresh {
+				f := fingerprinter.Fingerprint(item)
+				got = append(got, f.Path+" "+f.Entity+" "+f.Message)
+			}
+			if !reflect.DeepEqual(got, tt.fresh) {
+				t.Errorf("new diagnostics = %q, want %q", got, tt.fresh)
+			}
+
+			var gotFixed []Entry
+			for _, entry := range fixed {
+				gotFixed = append(gotFixed, *entry)
+			}
+			if !reflect.DeepEqual(gotFixed, tt.fixed) {
+				t.Errorf("fixed = %+v, want %+v", gotFixed, tt.fixed)
+			}
+		})
+	}
+}
+
+func TestRead_Version(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, []byte(`{"version": 2, "entries": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(path); err == nil {
+		t.Errorf("Read() of a newer baseline succeeded")
+	}
+}
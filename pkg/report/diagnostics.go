@@ -6,13 +6,30 @@ import (
 	"github.com/unLomTrois/gock3/internal/app/files"
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 	"github.com/unLomTrois/gock3/pkg/report/severity"
 )
 
 type DiagnosticItem struct {
+	// Rule is the ID of the check that produced the diagnostic
+	Rule     rules.ID
 	Severity severity.Severity
 	Pointer  *DiagnosticPointer
 	Msg      string
+	// Entity is the key of the entity the diagnostic is about, such as a trait, if there is one
+	Entity string
+
+	// Related are other places involved in the problem, such as the first definition of a duplicate
+	Related []*RelatedLocation
+	// Notes give context to the problem
+	Notes []string
+	// Help suggests how to fix the problem
+	Help []string
+	// Fixes are changes of the source that solve the problem, see Fix
+	Fixes []*Fix
+
+	// Caller is the Go file and line that added the diagnostic, see TraceCallers
+	Caller string
 }
 
 type DiagnosticPointer struct {
@@ -20,34 +37,78 @@ type DiagnosticPointer struct {
 	Length int
 }
 
+// TokenPointer points at the source text of a token
+func TokenPointer(token *tokens.Token) *DiagnosticPointer {
+	// Prefer the length of the source text, since the parser unquotes strings
+	length := len(token.Value)
+	if !token.Span.IsEmpty() {
+		length = token.Span.Len()
+	}
+
+	return &DiagnosticPointer{
+		Loc:    token.Loc,
+		Length: length,
+	}
+}
+
+// RelatedLocation is a place related to a diagnostic, with a message of its own
+type RelatedLocation struct {
+	Pointer *DiagnosticPointer
+	Msg     string
+}
+
 func (d *DiagnosticItem) Error() string {
-	return fmt.Sprintf("%s: %s", d.Severity, d.Msg)
+	return fmt.Sprintf("%s: %s [%s]", d.Severity, d.Msg, d.Rule)
 }
 
-func NewDiagnosticItem(severity severity.Severity, msg string, pointer *DiagnosticPointer) *DiagnosticItem {
+// WithEntity sets the key of the entity the diagnostic is about and returns it
+func (d *DiagnosticItem) WithEntity(key string) *DiagnosticItem {
+	d.Entity = key
+	return d
+}
+
+// WithRelated adds a related place to the diagnostic and returns it
+func (d *DiagnosticItem) WithRelated(pointer *DiagnosticPointer, msg string) *DiagnosticItem {
+	d.Related = append(d.Related, &RelatedLocation{Pointer: pointer, Msg: msg})
+	return d
+}
+
+// WithNote adds a note to the diagnostic and returns it
+func (d *DiagnosticItem) WithNote(note string) *DiagnosticItem {
+	d.Notes = append(d.Notes, note)
+	return d
+}
+
+// WithHelp adds a suggestion to the diagnostic and returns it
+func (d *DiagnosticItem) WithHelp(help string) *DiagnosticItem {
+	d.Help = append(d.Help, help)
+	return d
+}
+
+func NewDiagnosticItem(rule rules.ID, msg string, pointer *DiagnosticPointer) *DiagnosticItem {
 	return &DiagnosticItem{
-		Severity: severity,
+		Rule:     rule,
+		Severity: rules.DefaultSeverity(rule),
 		Msg:      msg,
 		Pointer:  pointer,
 	}
 }
 
-func FromToken(token *tokens.Token, severity severity.Severity, msg string) *DiagnosticItem {
+func FromToken(token *tokens.Token, rule rules.ID, msg string) *DiagnosticItem {
 	return &DiagnosticItem{
-		Severity: severity,
+		Rule:     rule,
+		Severity: rules.DefaultSeverity(rule),
 		Msg:      msg,
-		Pointer: &DiagnosticPointer{
-			Loc:    token.Loc,
-			Length: len(token.Value),
-		},
+		Pointer:  TokenPointer(token),
 	}
 }
 
-func FromFile(file *files.FileEntry, severity severity.Severity, msg string) *DiagnosticItem {
+func FromFile(file *files.FileEntry, rule rules.ID, msg string) *DiagnosticItem {
 	loc := tokens.LocFromFileEntry(file)
 
 	return &DiagnosticItem{
-		Severity: severity,
+		Rule:     rule,
+		Severity: rules.DefaultSeverity(rule),
 		Msg:      msg,
 		Pointer: &DiagnosticPointer{
 			Loc:    *loc,
@@ -56,11 +117,12 @@ func FromFile(file *files.FileEntry, severity severity.Severity, msg string) *Di
 	}
 }
 
-func FromBlock(file_block *ast.FileBlock, severity severity.Severity, msg string) *DiagnosticItem {
+func FromBlock(file_block *ast.FileBlock, rule rules.ID, msg string) *DiagnosticItem {
 	loc := file_block.Loc
 
 	return &DiagnosticItem{
-		Severity: severity,
+		Rule:     rule,
+		Severity: rules.DefaultSeverity(rule),
 		Msg:      msg,
 		Pointer: &DiagnosticPointer{
 			Loc:    loc,
@@ -72,9 +134,10 @@ func FromBlock(file_block *ast.FileBlock, severity severity.Severity, msg string
 // FromLoc creates a new DiagnosticItem from a loc
 // Primary used in cases when you know the loc but you don't know the token
 // Happens in Lexer
-func FromLoc(loc tokens.Loc, severity severity.Severity, msg string) *DiagnosticItem {
+func FromLoc(loc tokens.Loc, rule rules.ID, msg string) *DiagnosticItem {
 	return &DiagnosticItem{
-		Severity: severity,
+		Rule:     rule,
+		Severity: rules.DefaultSeverity(rule),
 		Msg:      msg,
 		Pointer: &DiagnosticPointer{
 			Loc:    loc,
@@ -4,30 +4,67 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"sync"
+	"sync/atomic"
 )
 
+// ErrorManager collects diagnostics. It never prints anything by itself:
+// every diagnostic is forwarded to the sinks of the manager, if it has any,
+// and all of them can be read back with Errors. It is safe for concurrent use.
 type ErrorManager struct {
+	mu     sync.Mutex
 	errors []*DiagnosticItem
+	sinks  []Sink
 }
 
-// todo: possibly we need to add mutex here
+// traceCallers is whether diagnostics record where in Go code they were added
+var traceCallers atomic.Bool
 
-func NewErrorManager() *ErrorManager {
+// TraceCallers turns on or off recording the Go file and line that add each diagnostic,
+// which helps to find the check behind a diagnostic when debugging
+func TraceCallers(enabled bool) {
+	traceCallers.Store(enabled)
+}
+
+func NewErrorManager(sinks ...Sink) *ErrorManager {
 	return &ErrorManager{
 		errors: make([]*DiagnosticItem, 0),
+		sinks:  sinks,
 	}
 }
 
-func (e *ErrorManager) AddError(item *DiagnosticItem) {
-	file, line := getCallerInfo(2) // Adjust skip to 2 to get caller of AddError.
+// AddSink makes the manager forward the diagnostics added from now on to sink
+func (e *ErrorManager) AddSink(sink Sink) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sinks = append(e.sinks, sink)
+}
 
-	fmt.Printf("%s:%d %s line: %d\n", file, line, item.Msg, item.Pointer.Loc.Line)
+func (e *ErrorManager) AddError(item *DiagnosticItem) {
+	if traceCallers.Load() {
+		file, line := getCallerInfo(2) // Adjust skip to 2 to get caller of AddError.
+		item.Caller = fmt.Sprintf("%s:%d", file, line)
+	}
 
+	e.mu.Lock()
 	e.errors = append(e.errors, item)
+	sinks := e.sinks
+	e.mu.Unlock()
+
+	for _, sink := range sinks {
+		sink.Report(item)
+	}
 }
 
+// Errors returns the diagnostics added so far
 func (e *ErrorManager) Errors() []*DiagnosticItem {
-	return e.errors
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	errors := make([]*DiagnosticItem, len(e.errors))
+	copy(errors, e.errors)
+	return errors
 }
 
 // getCallerInfo retrieves the caller's file, line number, and function name.
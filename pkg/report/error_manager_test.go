@@ -0,0 +1,145 @@
+package report
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// testLoc returns the location of line 2, column 3 in a temporary file
+func testLoc(t *testing.T) tokens.Loc {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte("a = b\nc = dé\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := *tokens.LocFromFileEntry(entry)
+	loc.Line, loc.Column = 2, 3
+	return loc
+}
+
+// captureStdout returns what f writes to os.Stdout
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	f()
+
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestErrorManager_Silent(t *testing.T) {
+	manager := NewErrorManager()
+
+	out := captureStdout(t, func() {
+		manager.AddError(FromLoc(testLoc(t), rules.ParseUnexpectedToken, "something is wrong"))
+	})
+
+	if out != "" {
+		t.Errorf("AddError printed %q", out)
+	}
+	if got := len(manager.Errors()); got != 1 {
+		t.Errorf("Errors() returned %d diagnostics, want 1", got)
+	}
+}
+
+func TestErrorManager_Concurrent(t *testing.T) {
+	sink := NewMemorySink()
+	manager := NewErrorManager(sink)
+	loc := testLoc(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				manager.AddError(FromLoc(loc, rules.ParseSkippedSyntax, "warning"))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(manager.Errors()); got != 800 {
+		t.Errorf("Errors() returned %d diagnostics, want 800", got)
+	}
+	if got := len(sink.Items()); got != 800 {
+		t.Errorf("the sink received %d diagnostics, want 800", got)
+	}
+}
+
+func TestErrorManager_TraceCallers(t *testing.T) {
+	manager := NewErrorManager()
+	loc := testLoc(t)
+
+	manager.AddError(FromLoc(loc, rules.ParseUnexpectedToken, "untraced"))
+
+	TraceCallers(true)
+	defer TraceCallers(false)
+	manager.AddError(FromLoc(loc, rules.ParseUnexpectedToken, "traced"))
+
+	errors := manager.Errors()
+	if errors[0].Caller != "" {
+		t.Errorf("Caller = %q without tracing", errors[0].Caller)
+	}
+	if !strings.HasPrefix(errors[1].Caller, "error_manager_test.go:") {
+		t.Errorf("Caller = %q, want the line of this test", errors[1].Caller)
+	}
+}
+
+func TestTerminalSink(t *testing.T) {
+	color.NoColor = true
+
+	var out bytes.Buffer
+	manager := NewErrorManager(NewTerminalSink(&out))
+
+	loc := testLoc(t)
+	manager.AddError(&DiagnosticItem{
+		Rule:     rules.Scoped(rules.ScopeTrait, rules.CheckUnexpectedValue),
+		Severity: severity.Error,
+		Msg:      "unknown value",
+		Pointer:  &DiagnosticPointer{Loc: loc, Length: 5},
+	})
+
+	path, _ := loc.Pathname()
+	want := "error[trait/unexpected-value]: unknown value\n" +
+		" --> " + path + ":2:3\n" +
+		"  |\n" +
+		"1 | a = b\n" +
+		"2 | c = dé\n" +
+		"  |   ^^^^\n" +
+		"  |\n" +
+		"\n"
+	if out.String() != want {
+		t.Errorf("printed\n%s\nwant\n%s", out.String(), want)
+	}
+}
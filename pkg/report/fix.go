@@ -0,0 +1,61 @@
+package report
+
+import (
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+)
+
+// Fix is a change of the source that solves the problem of a diagnostic.
+// Its edits are applied together or not at all.
+type Fix struct {
+	// Msg describes the change, such as "quote the version"
+	Msg   string
+	Edits []*TextEdit
+}
+
+// Applicable reports whether --fix may apply the fix, which only changes files of the mod on the disk
+func (f *Fix) Applicable() bool {
+	for _, edit := range f.Edits {
+		if edit.Loc.Kind() != files.Mod || !edit.Loc.GetIdx().OnDisk() {
+			return false
+		}
+	}
+	return true
+}
+
+// TextEdit replaces a range of bytes of a file with new text.
+// Offsets are counted the way token spans are, without the byte order mark.
+// An edit that deletes everything on a line but whitespace deletes the whole line.
+type TextEdit struct {
+	// Loc is the file and the position where the range starts
+	Loc     tokens.Loc
+	Span    tokens.Span
+	NewText string
+}
+
+// ReplaceToken returns an edit that replaces the source text of a token
+func ReplaceToken(token *tokens.Token, newText string) *TextEdit {
+	return &TextEdit{Loc: token.Loc, Span: token.Span, NewText: newText}
+}
+
+// DeleteSpan returns an edit that deletes a range of the file of loc, which starts at loc
+func DeleteSpan(loc tokens.Loc, span tokens.Span) *TextEdit {
+	return &TextEdit{Loc: loc, Span: span}
+}
+
+// WithFix adds a fix made of the given edits to the diagnostic and returns it.
+// Edits must replace at least one byte: the fix is left out if any edit has an empty span,
+// as the ones of tokens that were not read from a file do.
+func (d *DiagnosticItem) WithFix(msg string, edits ...*TextEdit) *DiagnosticItem {
+	if len(edits) == 0 {
+		return d
+	}
+	for _, edit := range edits {
+		if edit.Span.IsEmpty() {
+			return d
+		}
+	}
+
+	d.Fixes = append(d.Fixes, &Fix{Msg: msg, Edits: edits})
+	return d
+}
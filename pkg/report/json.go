@@ -0,0 +1,178 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+)
+
+// JSONVersion is the version of the JSON and NDJSON formats of diagnostics.
+// It changes only when fields are removed or change their meaning; new fields may be added at any time.
+const JSONVersion = 1
+
+// JSONDiagnostic is a diagnostic as written in the JSON and NDJSON formats
+type JSONDiagnostic struct {
+	Rule string `json:"rule,omitempty"`
+	// Severity is one of info, warning, error and critical
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Entity is the key of the entity the diagnostic is about, such as a trait
+	Entity string `json:"entity,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Origin is vanilla for files of the game and mod for files of the mod
+	Origin string `json:"origin,omitempty"`
+	// Mod is the name of the mod the file comes from, for files of the mods of a playset
+	Mod string `json:"mod,omitempty"`
+	// Line and Column start at 1, Column counts characters
+	Line   uint32 `json:"line"`
+	Column uint32 `json:"column"`
+	// Length is the length of the text the diagnostic points at, in bytes
+	Length int    `json:"length"`
+	Caller string `json:"caller,omitempty"`
+
+	Related []JSONRelated `json:"related,omitempty"`
+	Notes   []string      `json:"notes,omitempty"`
+	Help    []string      `json:"help,omitempty"`
+	Fixes   []JSONFix     `json:"fixes,omitempty"`
+}
+
+// JSONFix is a fix of a diagnostic, whose edits are applied together
+type JSONFix struct {
+	Message string     `json:"message"`
+	Edits   []JSONEdit `json:"edits"`
+}
+
+// JSONEdit replaces the bytes from Start up to End of a file with Text.
+// Offsets don't count the byte order mark.
+type JSONEdit struct {
+	Path  string `json:"path"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// JSONRelated is another place involved in a diagnostic, with the same location fields as JSONDiagnostic
+type JSONRelated struct {
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+	Origin  string `json:"origin,omitempty"`
+	Mod     string `json:"mod,omitempty"`
+	Line    uint32 `json:"line"`
+	Column  uint32 `json:"column"`
+	Length  int    `json:"length"`
+}
+
+// NewJSONDiagnostic converts a diagnostic to its JSON form
+func NewJSONDiagnostic(item *DiagnosticItem) JSONDiagnostic {
+	diagnostic := JSONDiagnostic{
+		Rule:     string(item.Rule),
+		Severity: strings.ToLower(item.Severity.String()),
+		Message:  item.Msg,
+		Entity:   item.Entity,
+		Caller:   item.Caller,
+		Notes:    item.Notes,
+		Help:     item.Help,
+	}
+
+	if item.Pointer != nil {
+		location := newJSONRelated(item.Pointer, "")
+		diagnostic.Path = location.Path
+		diagnostic.Origin = location.Origin
+		diagnostic.Mod = location.Mod
+		diagnostic.Line = location.Line
+		diagnostic.Column = location.Column
+		diagnostic.Length = location.Length
+	}
+
+	for _, related := range item.Related {
+		if related.Pointer != nil {
+			diagnostic.Related = append(diagnostic.Related, newJSONRelated(related.Pointer, related.Msg))
+		}
+	}
+
+	for _, fix := range item.Fixes {
+		jsonFix := JSONFix{Message: fix.Msg, Edits: make([]JSONEdit, len(fix.Edits))}
+		for i, edit := range fix.Edits {
+			path, _ := edit.Loc.Pathname()
+			jsonFix.Edits[i] = JSONEdit{Path: path, Start: edit.Span.Start, End: edit.Span.End, Text: edit.NewText}
+		}
+		diagnostic.Fixes = append(diagnostic.Fixes, jsonFix)
+	}
+
+	return diagnostic
+}
+
+func newJSONRelated(pointer *DiagnosticPointer, msg string) JSONRelated {
+	loc := pointer.Loc
+	related := JSONRelated{
+		Message: msg,
+		Line:    loc.Line,
+		Column:  loc.Column,
+		Length:  pointer.Length,
+	}
+	if path, err := loc.Pathname(); err == nil {
+		related.Path = path
+		related.Origin = loc.Kind().String()
+		related.Mod = loc.Source().Name
+	}
+	return related
+}
+
+// jsonDocument is the output of WriteJSON
+type jsonDocument struct {
+	Version     int              `json:"version"`
+	Diagnostics []JSONDiagnostic `json:"diagnostics"`
+}
+
+// ndjsonRecord is a line of the NDJSON output, which carries the version itself
+type ndjsonRecord struct {
+	Version int `json:"version"`
+	JSONDiagnostic
+}
+
+// WriteJSON writes diagnostics as a single JSON document with the format version and a list of diagnostics
+func WriteJSON(w io.Writer, items []*DiagnosticItem) error {
+	document := jsonDocument{
+		Version:     JSONVersion,
+		Diagnostics: make([]JSONDiagnostic, len(items)),
+	}
+	for i, item := range items {
+		document.Diagnostics[i] = NewJSONDiagnostic(item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// WriteNDJSON writes every diagnostic as a JSON object on a line of its own
+func WriteNDJSON(w io.Writer, items []*DiagnosticItem) error {
+	sink := NewJSONSink(w)
+	for _, item := range items {
+		sink.Report(item)
+	}
+	return sink.err
+}
+
+// JSONSink writes every diagnostic as a JSON object on a line of its own, as in WriteNDJSON
+type JSONSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	// err is the first error of writing to the output
+	err error
+}
+
+func NewJSONSink(out io.Writer) *JSONSink {
+	return &JSONSink{encoder: json.NewEncoder(out)}
+}
+
+func (s *JSONSink) Report(item *DiagnosticItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.encoder.Encode(ndjsonRecord{Version: JSONVersion, JSONDiagnostic: NewJSONDiagnostic(item)})
+	if s.err == nil {
+		s.err = err
+	}
+}
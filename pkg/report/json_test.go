@@ -0,0 +1,161 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+func TestJSONSink(t *testing.T) {
+	var out bytes.Buffer
+	manager := NewErrorManager(NewJSONSink(&out))
+
+	loc := testLoc(t)
+	manager.AddError(FromLoc(loc, rules.ParseSkippedSyntax, "first"))
+	manager.AddError(FromLoc(loc, rules.LexUnexpectedCharacter, "second"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrote %d lines, want 2: %q", len(lines), out.String())
+	}
+
+	var got ndjsonRecord
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := JSONDiagnostic{
+		Rule:     "lex/unexpected-character",
+		Severity: "critical",
+		Message:  "second",
+		Path:     got.Path,
+		Origin:   "mod",
+		Line:     2,
+		Column:   3,
+	}
+	if got.Version != JSONVersion || !reflect.DeepEqual(got.JSONDiagnostic, want) || filepath.Base(got.Path) != "test.txt" {
+		t.Errorf("second diagnostic = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	modLoc := testLoc(t)
+	gameRoot := t.TempDir()
+	writeFile(t, gameRoot, "vanilla.txt", "a = b")
+	vanilla, err := files.OpenFileEntry(nil, nil, filepath.Join(gameRoot, "vanilla.txt"), files.Vanilla)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vanillaLoc := *tokens.LocFromFileEntry(vanilla)
+
+	items := []*DiagnosticItem{
+		FromLoc(modLoc, rules.ParseMissingValue, "missing"),
+		{
+			Rule:     rules.ParseSkippedSyntax,
+			Severity: rules.DefaultSeverity(rules.ParseSkippedSyntax),
+			Msg:      "skipped",
+			Pointer:  &DiagnosticPointer{Loc: vanillaLoc, Length: 5},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := WriteJSON(&out, items); err != nil {
+		t.Fatal(err)
+	}
+
+	var document jsonDocument
+	if err := json.Unmarshal(out.Bytes(), &document); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	if document.Version != JSONVersion || len(document.Diagnostics) != 2 {
+		t.Fatalf("unexpected document:\n%s", out.String())
+	}
+
+	tests := []struct {
+		severity string
+		origin   string
+		length   int
+	}{
+		{"error", "mod", 0},
+		{"warning", "vanilla", 5},
+	}
+	for i, tt := range tests {
+		got := document.Diagnostics[i]
+		if got.Severity != tt.severity || got.Origin != tt.origin || got.Length != tt.length {
+			t.Errorf("diagnostic %d = %+v, want severity %s, origin %s and length %d", i, got, tt.severity, tt.origin, tt.length)
+		}
+	}
+}
+
+func TestNewJSONDiagnostic_Related(t *testing.T) {
+	loc := testLoc(t)
+	first := loc
+	first.Line = 1
+
+	item := FromLoc(loc, rules.ParseMissingValue, "missing").
+		WithRelated(&DiagnosticPointer{Loc: first, Length: 2}, "first here").
+		WithNote("a note").
+		WithHelp("a suggestion")
+
+	got := NewJSONDiagnostic(item)
+
+	want := []JSONRelated{{Message: "first here", Path: got.Path, Origin: "mod", Line: 1, Column: 3, Length: 2}}
+	if !reflect.DeepEqual(got.Related, want) {
+		t.Errorf("Related = %+v, want %+v", got.Related, want)
+	}
+	if !reflect.DeepEqual(got.Notes, []string{"a note"}) || !reflect.DeepEqual(got.Help, []string{"a suggestion"}) {
+		t.Errorf("Notes = %q, Help = %q", got.Notes, got.Help)
+	}
+}
+
+func TestWriteJSON_Empty(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteJSON(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), `"diagnostics": []`) {
+		t.Errorf("output should have an empty list of diagnostics:\n%s", out.String())
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	loc := testLoc(t)
+
+	var out bytes.Buffer
+	if err := WriteNDJSON(&out, []*DiagnosticItem{FromLoc(loc, rules.ParseMissingKey, "a"), FromLoc(loc, rules.ParseMissingKey, "b")}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i+1, err)
+		}
+		if record["version"] != float64(JSONVersion) || record["rule"] != "parse/missing-key" {
+			t.Errorf("line %d = %v", i+1, record)
+		}
+	}
+}
+
+func TestNewJSONDiagnostic_Fixes(t *testing.T) {
+	loc := testLoc(t)
+
+	item := FromLoc(loc, rules.ParseComparisonWithBlock, "compared").
+		WithFix("use =", &TextEdit{Loc: loc, Span: tokens.Span{Start: 8, End: 10}, NewText: "="}).
+		WithFix("ignored, the token was not read from the file", ReplaceToken(&tokens.Token{Loc: loc}, "x"))
+
+	got := NewJSONDiagnostic(item)
+
+	want := []JSONFix{{Message: "use =", Edits: []JSONEdit{{Path: got.Path, Start: 8, End: 10, Text: "="}}}}
+	if !reflect.DeepEqual(got.Fixes, want) {
+		t.Errorf("Fixes = %+v, want %+v", got.Fixes, want)
+	}
+}
@@ -0,0 +1,259 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/cache"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// Renderer prints diagnostics the way rustc does: a header with the severity, the rule and the message,
+// the location, the lines around it with the text the diagnostic points at underlined, and notes.
+//
+//	error[trait/out-of-range]: expected number in range [0, 1]
+//	 --> common/traits/brave.txt:3:10
+//	  |
+//	2 |     genetic = yes
+//	3 |     birth = 2
+//	  |             ^
+//	4 | }
+//	  |
+//	  = note: reported at block_validator.go:185
+//
+// Related locations follow the main one with ::: instead of --> and are underlined with dashes and their message.
+// Locations in a file of a mod of a playset are followed by the name of the mod, as in (mod "My Mod").
+// Notes and help go last. Tabs are expanded and wide characters take two columns, so the carets line up in a terminal.
+// Locations past the end of their line or file are clamped rather than trusted.
+type Renderer struct {
+	// Context is the number of lines shown before and after the line of the diagnostic
+	Context int
+	// Color is whether to color the output with ANSI escape codes
+	Color bool
+	// TabWidth is the number of columns between tab stops
+	TabWidth int
+
+	files *cache.FileCache
+}
+
+func NewRenderer(fileCache *cache.FileCache, colored bool) *Renderer {
+	return &Renderer{
+		Context:  1,
+		Color:    colored,
+		TabWidth: tokens.DefaultTabWidth,
+		files:    fileCache,
+	}
+}
+
+// Render returns the diagnostic as text that ends with a newline
+func (r *Renderer) Render(item *DiagnosticItem) string {
+	var out strings.Builder
+
+	header := strings.ToLower(item.Severity.String())
+	if item.Rule != "" {
+		header += "[" + string(item.Rule) + "]"
+	}
+	out.WriteString(r.style(severityColor(item.Severity), color.Bold).Sprint(header))
+	out.WriteString(r.style(color.Bold).Sprint(": " + item.Msg))
+	out.WriteString("\n")
+
+	// The main location is followed by the related ones, all sharing one gutter
+	var spans []*span
+	if item.Pointer != nil {
+		spans = append(spans, r.span(item.Pointer, "-->", "^", severityColor(item.Severity), ""))
+	}
+	for _, related := range item.Related {
+		if related.Pointer != nil {
+			spans = append(spans, r.span(related.Pointer, ":::", "-", color.FgBlue, related.Msg))
+		}
+	}
+
+	gutter := 1
+	for _, s := range spans {
+		gutter = max(gutter, len(strconv.Itoa(s.last)))
+	}
+	for _, s := range spans {
+		r.snippet(&out, s, gutter)
+	}
+
+	notes := item.Notes
+	if item.Caller != "" {
+		notes = append(notes[:len(notes):len(notes)], "reported at "+item.Caller)
+	}
+
+	equals := r.style(color.FgBlue, color.Bold).Sprint("=")
+	margin := strings.Repeat(" ", gutter)
+	for _, note := range notes {
+		fmt.Fprintf(&out, "%s %s %s\n", margin, equals, r.style(color.Bold).Sprint("note:")+" "+note)
+	}
+	help := item.Help
+	for _, fix := range item.Fixes {
+		line := fix.Msg
+		if fix.Applicable() {
+			line += " (fixable with --fix)"
+		}
+		help = append(help[:len(help):len(help)], line)
+	}
+	for _, line := range help {
+		fmt.Fprintf(&out, "%s %s %s\n", margin, equals, r.style(color.Bold).Sprint("help:")+" "+line)
+	}
+
+	return out.String()
+}
+
+// span is the part of a file shown for a location
+type span struct {
+	pointer *DiagnosticPointer
+	path    string
+	// mod is the name of the mod the file comes from, empty for the game
+	mod string
+	// lines of the file, nil if it can't be read
+	lines []string
+	// known is whether the line of the location is in the file
+	known       bool
+	first, last int
+
+	arrow  string
+	marker string
+	color  color.Attribute
+	label  string
+}
+
+func (r *Renderer) span(pointer *DiagnosticPointer, arrow string, marker string, markerColor color.Attribute, label string) *span {
+	loc := pointer.Loc
+	lineNumber := int(loc.Line)
+
+	s := &span{pointer: pointer, arrow: arrow, marker: marker, color: markerColor, label: label}
+
+	path, err := loc.Pathname()
+	if err != nil {
+		path = "<unknown>"
+	}
+	s.path = path
+	s.mod = loc.Source().Name
+
+	if r.files != nil && err == nil {
+		s.lines, _ = r.files.Lines(loc.GetIdx())
+	}
+	s.known = lineNumber >= 1 && lineNumber <= len(s.lines)
+
+	s.first, s.last = lineNumber, lineNumber
+	if s.known {
+		s.first = max(1, lineNumber-r.Context)
+		s.last = min(len(s.lines), lineNumber+r.Context)
+		// A line ending at the end of the file doesn't start another line worth showing
+		if s.last > lineNumber && s.last == len(s.lines) && s.lines[s.last-1] == "" {
+			s.last--
+		}
+	}
+
+	return s
+}
+
+// snippet prints the location and the source lines of a span, with a gutter of the given width
+func (r *Renderer) snippet(out *strings.Builder, s *span, width int) {
+	loc := s.pointer.Loc
+	lineNumber := int(loc.Line)
+
+	blue := r.style(color.FgBlue, color.Bold)
+	margin := strings.Repeat(" ", width)
+
+	location := fmt.Sprintf("%s:%d:%d", s.path, loc.Line, loc.Column)
+	if s.mod != "" {
+		location += fmt.Sprintf(" (mod %q)", s.mod)
+	}
+	fmt.Fprintf(out, "%s%s %s\n", margin, blue.Sprint(s.arrow), location)
+	if !s.known {
+		if s.label != "" {
+			fmt.Fprintf(out, "%s %s %s\n", margin, blue.Sprint("|"), r.style(s.color, color.Bold).Sprint(s.label))
+		}
+		return
+	}
+
+	fmt.Fprintf(out, "%s %s\n", margin, blue.Sprint("|"))
+	for n := s.first; n <= s.last; n++ {
+		text := s.lines[n-1]
+
+		number := blue.Sprint(fmt.Sprintf("%*d |", width, n))
+		if text == "" {
+			fmt.Fprintf(out, "%s\n", number)
+		} else {
+			fmt.Fprintf(out, "%s %s\n", number, r.expandTabs(text))
+		}
+
+		if n == lineNumber {
+			start, length := r.underline(text, int(loc.Column), s.pointer.Length)
+			marker := strings.Repeat(s.marker, length)
+			if s.label != "" {
+				marker += " " + s.label
+			}
+			fmt.Fprintf(out, "%s %s %s%s\n", margin, blue.Sprint("|"), strings.Repeat(" ", start), r.style(s.color, color.Bold).Sprint(marker))
+		}
+	}
+	fmt.Fprintf(out, "%s %s\n", margin, blue.Sprint("|"))
+}
+
+// underline returns the display column where the carets start and how many there are.
+// Text past the end of the line is cut off, and there is at least one caret.
+func (r *Renderer) underline(text string, column int, length int) (int, int) {
+	line := []byte(text)
+	start := tokens.ColumnOffset(line, column)
+	end := min(start+max(length, 0), len(line))
+
+	startColumn := tokens.VisualWidth(line[:start], r.TabWidth)
+	endColumn := tokens.VisualWidth(line[:end], r.TabWidth)
+
+	return startColumn, max(1, endColumn-startColumn)
+}
+
+// expandTabs replaces tabs with the spaces up to the next tab stop
+func (r *Renderer) expandTabs(text string) string {
+	if !strings.Contains(text, "\t") {
+		return text
+	}
+
+	var out strings.Builder
+	column := 0
+	for _, c := range text {
+		if c == '\t' {
+			spaces := r.TabWidth - column%r.TabWidth
+			out.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+			continue
+		}
+
+		var buf [utf8.UTFMax]byte
+		n := utf8.EncodeRune(buf[:], c)
+		out.Write(buf[:n])
+		column += tokens.VisualWidth(buf[:n], r.TabWidth)
+	}
+
+	return out.String()
+}
+
+func (r *Renderer) style(attributes ...color.Attribute) *color.Color {
+	c := color.New(attributes...)
+	if r.Color {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+	return c
+}
+
+func severityColor(s severity.Severity) color.Attribute {
+	switch s {
+	case severity.Critical:
+		return color.FgHiMagenta
+	case severity.Error:
+		return color.FgRed
+	case severity.Warning:
+		return color.FgYellow
+	default:
+		return color.FgCyan
+	}
+}
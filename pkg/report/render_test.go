@@ -0,0 +1,233 @@
+package report
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/cache"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    uint32
+		column  uint32
+		length  int
+		caller  string
+		// want has PATH in place of the path of the file
+		want string
+	}{
+		{
+			name:    "context lines",
+			content: "a = {\n\tbirth = 2\n\tdeath = 1\n}\n",
+			line:    2, column: 10, length: 1,
+			want: "error[parse/missing-value]: message\n" +
+				" --> PATH:2:10\n" +
+				"  |\n" +
+				"1 | a = {\n" +
+				"2 |     birth = 2\n" +
+				"  |             ^\n" +
+				"3 |     death = 1\n" +
+				"  |\n",
+		},
+		{
+			name:    "wide characters",
+			content: "name = 名前 x",
+			line:    1, column: 8, length: len("名前"),
+			want: "error[parse/missing-value]: message\n" +
+				" --> PATH:1:8\n" +
+				"  |\n" +
+				"1 | name = 名前 x\n" +
+				"  |        ^^^^\n" +
+				"  |\n",
+		},
+		{
+			name:    "column past the end of the line",
+			content: "a = b\n",
+			line:    1, column: 40, length: 3,
+			want: "error[parse/missing-value]: message\n" +
+				" --> PATH:1:40\n" +
+				"  |\n" +
+				"1 | a = b\n" +
+				"  |      ^\n" +
+				"  |\n",
+		},
+		{
+			name:    "length past the end of the line",
+			content: "a = \"b\nc\"\n",
+			line:    1, column: 5, length: 5,
+			want: "error[parse/missing-value]: message\n" +
+				" --> PATH:1:5\n" +
+				"  |\n" +
+				"1 | a = \"b\n" +
+				"  |     ^^\n" +
+				"2 | c\"\n" +
+				"  |\n",
+		},
+		{
+			name:    "line past the end of the file",
+			content: "a = b\n",
+			line:    99, column: 1,
+			want: "error[parse/missing-value]: message\n" +
+				"  --> PATH:99:1\n",
+		},
+		{
+			name:    "empty line at the end of the file",
+			content: "a = {\n",
+			line:    2, column: 1,
+			want: "error[parse/missing-value]: message\n" +
+				" --> PATH:2:1\n" +
+				"  |\n" +
+				"1 | a = {\n" +
+				"2 |\n" +
+				"  | ^\n" +
+				"  |\n",
+		},
+		{
+			name:    "double digit line numbers",
+			content: strings.Repeat("a = b\n", 9) + "c = d\nd = e\n",
+			line:    10, column: 1, length: 1,
+			caller: "trait.go:12",
+			want: "error[parse/missing-value]: message\n" +
+				"  --> PATH:10:1\n" +
+				"   |\n" +
+				" 9 | a = b\n" +
+				"10 | c = d\n" +
+				"   | ^\n" +
+				"11 | d = e\n" +
+				"   |\n" +
+				"   = note: reported at trait.go:12\n",
+		},
+		{
+			name:    "byte order mark",
+			content: "\uFEFFa = b",
+			line:    1, column: 5, length: 1,
+			want: "error[parse/missing-value]: message\n" +
+				" --> PATH:1:5\n" +
+				"  |\n" +
+				"1 | a = b\n" +
+				"  |     ^\n" +
+				"  |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := writeFile(t, t.TempDir(), "test.txt", tt.content)
+			loc.Line, loc.Column = tt.line, tt.column
+			path, _ := loc.Pathname()
+
+			item := NewDiagnosticItem(rules.ParseMissingValue, "message", &DiagnosticPointer{Loc: loc, Length: tt.length})
+			item.Caller = tt.caller
+
+			got := NewRenderer(cache.NewFileCache(), false).Render(item)
+			if want := strings.ReplaceAll(tt.want, "PATH", path); got != want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderer_Related(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFile(t, dir, "a.txt", "brave = {}\n")
+	loc := writeFile(t, dir, "b.txt", strings.Repeat("\n", 11)+"brave = {}\n")
+	loc.Line = 12
+	firstPath, _ := first.Pathname()
+	path, _ := loc.Pathname()
+
+	item := FromLoc(loc, rules.ParseMissingValue, "brave is defined twice").
+		WithRelated(&DiagnosticPointer{Loc: first, Length: len("brave")}, "first definition here").
+		WithNote("the second definition is ignored").
+		WithHelp("rename one of them")
+	item.Pointer.Length = len("brave")
+	item.Caller = "symbol_table.go:10"
+
+	want := "error[parse/missing-value]: brave is defined twice\n" +
+		"  --> " + path + ":12:1\n" +
+		"   |\n" +
+		"11 |\n" +
+		"12 | brave = {}\n" +
+		"   | ^^^^^\n" +
+		"   |\n" +
+		"  ::: " + firstPath + ":1:1\n" +
+		"   |\n" +
+		" 1 | brave = {}\n" +
+		"   | ----- first definition here\n" +
+		"   |\n" +
+		"   = note: the second definition is ignored\n" +
+		"   = note: reported at symbol_table.go:10\n" +
+		"   = help: rename one of them\n"
+
+	if got := NewRenderer(cache.NewFileCache(), false).Render(item); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+	if len(item.Notes) != 1 {
+		t.Errorf("Render() changed the notes of the diagnostic: %q", item.Notes)
+	}
+}
+
+func TestRenderer_Mod(t *testing.T) {
+	game, mod := t.TempDir(), t.TempDir()
+	writeFile(t, mod, "common/traits/t.txt", "brave = {}\n")
+
+	entries, err := files.ScanPlayset(game, files.NewModLoader("My Mod", mod, nil))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ScanPlayset() = %v, %v", entries, err)
+	}
+	loc := *tokens.LocFromFileEntry(entries[0])
+	loc.Line, loc.Column = 1, 1
+	path, _ := loc.Pathname()
+
+	got := NewRenderer(cache.NewFileCache(), false).Render(FromLoc(loc, rules.ParseMissingValue, "message"))
+	if want := " --> " + path + ":1:1 (mod \"My Mod\")\n"; !strings.Contains(got, want) {
+		t.Errorf("Render() =\n%s\nwant the location\n%s", got, want)
+	}
+
+	if diagnostic := NewJSONDiagnostic(FromLoc(loc, rules.ParseMissingValue, "message")); diagnostic.Mod != "My Mod" {
+		t.Errorf("JSON mod = %q, want %q", diagnostic.Mod, "My Mod")
+	}
+}
+
+func TestRenderer_MissingFile(t *testing.T) {
+	dir := t.TempDir()
+	loc := writeFile(t, dir, "test.txt", "a = b")
+	loc.Line, loc.Column = 1, 5
+	path, _ := loc.Pathname()
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got := NewRenderer(cache.NewFileCache(), false).Render(FromLoc(loc, rules.ParseMissingValue, "message"))
+	if want := "error[parse/missing-value]: message\n --> " + path + ":1:5\n"; got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderer_Color(t *testing.T) {
+	loc := writeFile(t, t.TempDir(), "test.txt", "a = b")
+	item := FromLoc(loc, rules.ParseMissingValue, "message")
+
+	if got := NewRenderer(cache.NewFileCache(), true).Render(item); !strings.Contains(got, "\x1b[") {
+		t.Errorf("colored output has no escape codes:\n%q", got)
+	}
+	if got := NewRenderer(cache.NewFileCache(), false).Render(item); strings.Contains(got, "\x1b[") {
+		t.Errorf("plain output has escape codes:\n%q", got)
+	}
+}
+
+func TestRenderer_Fix(t *testing.T) {
+	loc := writeFile(t, t.TempDir(), "test.txt", "a == { }")
+	item := FromLoc(loc, rules.ParseComparisonWithBlock, "message").
+		WithFix("replace `==` with `=`", &TextEdit{Loc: loc, Span: tokens.Span{Start: 2, End: 4}, NewText: "="})
+
+	got := NewRenderer(cache.NewFileCache(), false).Render(item)
+	if want := "  = help: replace `==` with `=` (fixable with --fix)\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("Render() =\n%s\nwant it to end with\n%s", got, want)
+	}
+}
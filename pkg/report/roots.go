@@ -0,0 +1,73 @@
+package report
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Roots are the directories that the paths of diagnostics are relative to, such as the root of the mod
+type Roots struct {
+	roots []root
+}
+
+// root is an absolute root and its position among the paths given to NewRoots
+type root struct {
+	path  string
+	index int
+}
+
+// NewRoots returns the roots of the paths. Empty paths are left out.
+func NewRoots(paths ...string) *Roots {
+	roots := make([]root, 0, len(paths))
+	for i, path := range paths {
+		if path == "" {
+			continue
+		}
+		if path, err := filepath.Abs(path); err == nil {
+			roots = append(roots, root{path: path, index: i})
+		}
+	}
+
+	// The innermost root wins when roots are nested
+	sort.SliceStable(roots, func(i, j int) bool {
+		return len(roots[i].path) > len(roots[j].path)
+	})
+
+	return &Roots{roots: roots}
+}
+
+// Root returns the absolute path of the root given at the index to NewRoots, and false if it was left out
+func (r *Roots) Root(index int) (string, bool) {
+	for _, root := range r.roots {
+		if root.index == index {
+			return root.path, true
+		}
+	}
+	return "", false
+}
+
+// Find returns the index given to NewRoots of the root that contains the path, and the path relative to it
+// with forward slashes. A path outside every root is returned absolute, with the index -1.
+func (r *Roots) Find(path string) (int, string) {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+
+	for _, root := range r.roots {
+		relative, err := filepath.Rel(root.path, path)
+		if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return root.index, filepath.ToSlash(relative)
+	}
+
+	return -1, filepath.ToSlash(path)
+}
+
+// Relative returns the path relative to the root that contains it, with forward slashes.
+// A path outside every root is returned absolute, and false.
+func (r *Roots) Relative(path string) (string, bool) {
+	index, relative := r.Find(path)
+	return relative, index >= 0
+}
@@ -0,0 +1,249 @@
+// Package rules lists the checks that produce diagnostics.
+//
+// Every diagnostic carries the ID of the rule that produced it, such as parse/unclosed-block
+// or trait/banned-field. IDs are stable: they are used to filter, suppress and configure diagnostics,
+// so a rule keeps its ID when its message changes.
+package rules
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// ID identifies a rule, in the form category/name
+type ID string
+
+// Category returns the part of the ID before the slash, such as "parse" for parse/unclosed-block
+func (id ID) Category() string {
+	category, _, _ := strings.Cut(string(id), "/")
+	return category
+}
+
+// Matches reports whether the pattern selects the rule. A pattern is an ID, or a category
+// written as trait or trait/*.
+func (id ID) Matches(pattern string) bool {
+	category := id.Category()
+	return pattern == string(id) || pattern == category || pattern == category+"/*"
+}
+
+// Rule describes a check
+type Rule struct {
+	ID ID
+	// Severity is the severity of the diagnostics of the rule, unless configured otherwise
+	Severity severity.Severity
+	// Summary is a one-line description of what the rule reports
+	Summary string
+	// Description explains why the rule exists and how to fix what it reports
+	Description string
+}
+
+// Lexer rules
+const (
+	LexUnexpectedCharacter ID = "lex/unexpected-character"
+)
+
+// Parser rules
+const (
+	ParseUnexpectedToken ID = "parse/unexpected-token"
+	ParseUnexpectedEOF   ID = "parse/unexpected-eof"
+	ParseUnclosedBlock   ID = "parse/unclosed-block"
+	ParseUnmatchedBrace  ID = "parse/unmatched-brace"
+	ParseMissingKey      ID = "parse/missing-key"
+	ParseMissingOperator ID = "parse/missing-operator"
+	ParseMissingValue    ID = "parse/missing-value"
+	ParseInvalidString   ID = "parse/invalid-string"
+	ParseInvalidDate     ID = "parse/invalid-date"
+	ParseSkippedSyntax   ID = "parse/skipped-syntax"
+	ParseRecoveryFailed  ID = "parse/recovery-failed"
+	ParseNonLiteralValue ID = "parse/non-literal-value"
+
+	ParseComparisonWithBlock ID = "parse/comparison-with-block"
+)
+
+// Suppression rules
+const (
+	SuppressionUnused           ID = "suppression/unused"
+	SuppressionUnknownDirective ID = "suppression/unknown-directive"
+)
+
+// Config rules
+const (
+	ConfigUnknownField ID = "config/unknown-field"
+	ConfigInvalidValue ID = "config/invalid-value"
+)
+
+// Playset rules
+const (
+	ModMissingDependency ID = "mod/missing-dependency"
+	ModDependencyCycle   ID = "mod/dependency-cycle"
+)
+
+// Entity rules
+const (
+	CharacterDeathOrder ID = "character/death-before-birth"
+	CharacterDuplicate  ID = "character/duplicate"
+	TraitDuplicate      ID = "trait/duplicate"
+)
+
+// Scope is the kind of entity a validator checks, which becomes the category of the IDs of its checks
+type Scope string
+
+const (
+	ScopeBlock     Scope = "block"
+	ScopeTrait     Scope = "trait"
+	ScopeCharacter Scope = "character"
+	ScopeMod       Scope = "mod"
+)
+
+// Check is a check of the generic validators, which is reported under the scope of the validator,
+// so that the same check on traits and on characters can be configured separately
+type Check string
+
+const (
+	CheckExpectedBlock   Check = "expected-block"
+	CheckExpectedToken   Check = "expected-token"
+	CheckUnexpectedValue Check = "unexpected-value"
+	CheckWrongType       Check = "wrong-type"
+	CheckOutOfRange      Check = "out-of-range"
+	CheckMissingField    Check = "missing-field"
+	CheckBannedField     Check = "banned-field"
+)
+
+// Scoped returns the ID of a check in a scope, such as trait/banned-field
+func Scoped(scope Scope, check Check) ID {
+	return ID(string(scope) + "/" + string(check))
+}
+
+var scopes = []Scope{ScopeBlock, ScopeTrait, ScopeCharacter, ScopeMod}
+
+var checks = []struct {
+	check       Check
+	summary     string
+	description string
+}{
+	{CheckExpectedBlock, "A field has a single value where a block is expected",
+		"The field must be written as `key = { ... }`."},
+	{CheckExpectedToken, "A field has a block where a single value is expected",
+		"The field must be written as `key = value`, without braces."},
+	{CheckUnexpectedValue, "A field has a value outside of the allowed ones",
+		"The game only understands a fixed set of values for this field. Check the spelling of the value."},
+	{CheckWrongType, "A value has the wrong type",
+		"The value must be of another type, such as a number, a boolean (yes or no) or a quoted string."},
+	{CheckOutOfRange, "A number is out of the allowed range",
+		"The game expects the number to be within a range, and may clamp or ignore values outside of it."},
+	{CheckMissingField, "A required field is missing",
+		"The game needs this field to load the entity."},
+	{CheckBannedField, "A field is not allowed here",
+		"The field conflicts with other fields of the entity, and the game ignores it or misbehaves."},
+}
+
+var rules = []Rule{
+	{LexUnexpectedCharacter, severity.Critical, "A character that is not part of the script syntax",
+		"The game can't read the file past this character. Non-ASCII text must be in a quoted string."},
+
+	{ParseUnexpectedToken, severity.Error, "A token in a place where it is not allowed",
+		"The syntax around this token is broken, for example an operator without a key."},
+	{ParseUnexpectedEOF, severity.Error, "The file ends in the middle of a field",
+		"The file is probably truncated, or a quote or a brace is missing before the end."},
+	{ParseUnclosedBlock, severity.Error, "A block is not closed before the end of the file",
+		"A closing brace is missing. The game reads the rest of the file into the block."},
+	{ParseUnmatchedBrace, severity.Error, "A closing brace without an opening one",
+		"An extra closing brace ends a block earlier than intended, and the fields after it go to the outer block."},
+	{ParseMissingKey, severity.Error, "A field without a key",
+		"Every field must start with a key, such as a word, a number or a date."},
+	{ParseMissingOperator, severity.Error, "A key without an operator",
+		"A key must be followed by an operator, such as `=` or `>=`."},
+	{ParseMissingValue, severity.Error, "A field without a value",
+		"The operator of the field is followed by the end of the block, of the file or by another field."},
+	{ParseInvalidString, severity.Error, "A quoted string that can't be read",
+		"The quoted string has an invalid escape sequence."},
+	{ParseInvalidDate, severity.Error, "A date that doesn't exist in the calendar",
+		"The month must be between 1 and 12 and the day must exist in the month. The game has no leap years."},
+	{ParseSkippedSyntax, severity.Warning, "Syntax skipped while recovering from an error",
+		"The parser ignored these tokens to continue after an error. They are not part of any field."},
+	{ParseRecoveryFailed, severity.Error, "The parser gave up recovering from an error",
+		"The syntax is too broken to continue, and the rest of the section is ignored."},
+	{ParseNonLiteralValue, severity.Error, "A value is expected, but the parser recovered to something else",
+		"A single value, such as a word, a number or a quoted string, is missing."},
+	{ParseComparisonWithBlock, severity.Warning, "A block after the `==` operator",
+		"`==` compares two values. A block is assigned with `=`."},
+
+	{SuppressionUnused, severity.Warning, "A gock3:ignore comment that suppresses nothing",
+		"The problem it was written for is gone, or the rule ID is misspelled. Remove the rule from the comment."},
+	{SuppressionUnknownDirective, severity.Warning, "A gock3: comment that gock3 doesn't understand",
+		"The directives are gock3:ignore for a line and gock3:ignore-file for a whole file."},
+
+	{ConfigUnknownField, severity.Warning, "A field of .gock3.txt that gock3 doesn't know",
+		"The field is ignored. It is probably misspelled, or belongs in another block."},
+	{ConfigInvalidValue, severity.Error, "A setting of .gock3.txt with a value of the wrong kind",
+		"The setting is ignored. Rules are given by ID or category, severities are info, warning, error or critical."},
+
+	{ModMissingDependency, severity.Warning, "A mod depends on a mod that is not in the playset",
+		"The dependencies of a descriptor name other mods, which should be loaded before it. " +
+			"Add the missing mod with --mod or enable it in the launcher, or check the spelling of its name."},
+	{ModDependencyCycle, severity.Error, "Mods depend on each other",
+		"The dependencies of the mods form a cycle, so no load order satisfies them. The mods keep the order they were given in."},
+
+	{CharacterDeathOrder, severity.Error, "A character dies before being born",
+		"The date of the death entry in the character history is earlier than the date of the birth entry."},
+	{CharacterDuplicate, severity.Warning, "A character is defined twice",
+		"Two characters of the game or two characters of the mod have the same ID. Only the last definition is used."},
+	{TraitDuplicate, severity.Warning, "A trait is defined twice",
+		"Two traits of the game or two traits of the mod have the same name. Only the last definition is used. " +
+			"Overriding a trait of the game in the mod is not a duplicate."},
+}
+
+var registry = make(map[ID]Rule)
+
+func init() {
+	for _, rule := range rules {
+		registry[rule.ID] = rule
+	}
+
+	for _, scope := range scopes {
+		for _, check := range checks {
+			id := Scoped(scope, check.check)
+			registry[id] = Rule{ID: id, Severity: severity.Error, Summary: check.summary, Description: check.description}
+		}
+	}
+}
+
+// Lookup returns the rule with the given ID
+func Lookup(id ID) (Rule, bool) {
+	rule, ok := registry[id]
+	return rule, ok
+}
+
+// DefaultSeverity returns the severity of the rule, or Error for rules that are not registered
+func DefaultSeverity(id ID) severity.Severity {
+	if rule, ok := registry[id]; ok {
+		return rule.Severity
+	}
+	return severity.Error
+}
+
+// Known reports whether the pattern selects at least one registered rule, see ID.Matches
+func Known(pattern string) bool {
+	for id := range registry {
+		if id.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns every registered rule, sorted by ID
+func All() []Rule {
+	result := make([]Rule, 0, len(registry))
+	for _, rule := range registry {
+		result = append(result, rule)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
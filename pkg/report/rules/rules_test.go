@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+var idPattern = regexp.MustCompile(`^[a-z]+/[a-z]+(-[a-z]+)*$`)
+
+func TestAll(t *testing.T) {
+	all := All()
+	if len(all) == 0 {
+		t.Fatal("no rules are registered")
+	}
+
+	for i, rule := range all {
+		if !idPattern.MatchString(string(rule.ID)) {
+			t.Errorf("%q is not of the form category/name", rule.ID)
+		}
+		if rule.Summary == "" || rule.Description == "" {
+			t.Errorf("%q is not described", rule.ID)
+		}
+		if i > 0 && all[i-1].ID >= rule.ID {
+			t.Errorf("%q comes after %q", rule.ID, all[i-1].ID)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		id       ID
+		ok       bool
+		severity severity.Severity
+	}{
+		{ParseUnclosedBlock, true, severity.Error},
+		{ParseSkippedSyntax, true, severity.Warning},
+		{LexUnexpectedCharacter, true, severity.Critical},
+		{Scoped(ScopeTrait, CheckBannedField), true, severity.Error},
+		{"trait/no-such-rule", false, severity.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.id), func(t *testing.T) {
+			rule, ok := Lookup(tt.id)
+			if ok != tt.ok {
+				t.Fatalf("Lookup(%q) found = %v, want %v", tt.id, ok, tt.ok)
+			}
+			if ok && rule.ID != tt.id {
+				t.Errorf("Lookup(%q) returned %q", tt.id, rule.ID)
+			}
+			if got := DefaultSeverity(tt.id); got != tt.severity {
+				t.Errorf("DefaultSeverity(%q) = %v, want %v", tt.id, got, tt.severity)
+			}
+		})
+	}
+}
+
+func TestScoped(t *testing.T) {
+	if got := Scoped(ScopeTrait, CheckBannedField); got != "trait/banned-field" {
+		t.Errorf("Scoped() = %q", got)
+	}
+	if got := Scoped(ScopeMod, CheckMissingField).Category(); got != "mod" {
+		t.Errorf("Category() = %q", got)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+		known   bool
+	}{
+		{"trait/duplicate", true, true},
+		{"trait", true, true},
+		{"trait/*", true, true},
+		{"trait/dup*", false, false},
+		{"character/duplicate", false, true},
+		{"nothing", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := TraitDuplicate.Matches(tt.pattern); got != tt.want {
+				t.Errorf("Matches(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+			if got := Known(tt.pattern); got != tt.known {
+				t.Errorf("Known(%q) = %v, want %v", tt.pattern, got, tt.known)
+			}
+		})
+	}
+}
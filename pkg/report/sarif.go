@@ -0,0 +1,375 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/utils"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	toolName           = "gock3"
+	toolInformationURI = "https://github.com/unLomTrois/gock3"
+)
+
+// SARIFRoot is a directory that file URIs are made relative to, such as the root of the mod
+type SARIFRoot struct {
+	// ID names the root in the log, such as MODROOT
+	ID   string
+	Path string
+}
+
+// WriteSARIF writes diagnostics as a SARIF 2.1.0 log, together with the description of their rules.
+// Files inside one of the roots get URIs relative to it, other files get absolute file URIs.
+// Columns are counted in characters, as in tokens.Loc.
+func WriteSARIF(w io.Writer, items []*DiagnosticItem, roots ...SARIFRoot) error {
+	log := newSARIFBuilder(roots).build(items)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                           `json:"columnKind"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	FullDescription      *sarifMessage       `json:"fullDescription,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	// RelatedLocations are numbered from 1 in the order of DiagnosticItem.Related
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifMessage `json:"insertedContent,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifBuilder struct {
+	// ids are the IDs of the roots, by their index in roots
+	ids   []string
+	roots *Roots
+	// lines of the files read so far, nil for files that can't be read
+	lines map[string]*tokens.LineIndex
+}
+
+func newSARIFBuilder(roots []SARIFRoot) *sarifBuilder {
+	ids := make([]string, len(roots))
+	paths := make([]string, len(roots))
+	for i, root := range roots {
+		ids[i], paths[i] = root.ID, root.Path
+	}
+
+	return &sarifBuilder{ids: ids, roots: NewRoots(paths...), lines: make(map[string]*tokens.LineIndex)}
+}
+
+func (b *sarifBuilder) build(items []*DiagnosticItem) sarifLog {
+	// Describe the rules in use, in a stable order
+	var ids []rules.ID
+	seen := make(map[rules.ID]bool)
+	for _, item := range items {
+		if item.Rule != "" && !seen[item.Rule] {
+			seen[item.Rule] = true
+			ids = append(ids, item.Rule)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	ruleIndex := make(map[rules.ID]int, len(ids))
+	descriptors := make([]sarifRule, len(ids))
+	for i, id := range ids {
+		ruleIndex[id] = i
+		descriptors[i] = sarifRule{ID: string(id)}
+		if rule, ok := rules.Lookup(id); ok {
+			descriptors[i].ShortDescription = &sarifMessage{Text: rule.Summary}
+			descriptors[i].FullDescription = &sarifMessage{Text: rule.Description}
+			descriptors[i].DefaultConfiguration = &sarifConfiguration{Level: sarifLevel(rule.Severity)}
+		}
+	}
+
+	results := make([]sarifResult, 0, len(items))
+	for _, item := range items {
+		result := sarifResult{
+			RuleID:  string(item.Rule),
+			Level:   sarifLevel(item.Severity),
+			Message: sarifMessage{Text: sarifText(item)},
+		}
+		if index, ok := ruleIndex[item.Rule]; ok {
+			result.RuleIndex = &index
+		}
+		if location, ok := b.location(item.Pointer); ok {
+			result.Locations = []sarifLocation{location}
+		}
+		for _, related := range item.Related {
+			location, ok := b.location(related.Pointer)
+			if !ok {
+				continue
+			}
+			id := len(result.RelatedLocations) + 1
+			location.ID = &id
+			location.Message = &sarifMessage{Text: related.Msg}
+			result.RelatedLocations = append(result.RelatedLocations, location)
+		}
+		for _, fix := range item.Fixes {
+			if sarifFix, ok := b.fix(fix); ok {
+				result.Fixes = append(result.Fixes, sarifFix)
+			}
+		}
+		results = append(results, result)
+	}
+
+	baseIDs := make(map[string]sarifArtifactLocation, len(b.ids))
+	for i, id := range b.ids {
+		if path, ok := b.roots.Root(i); ok {
+			baseIDs[id] = sarifArtifactLocation{URI: fileURI(path) + "/"}
+		}
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolInformationURI,
+				Rules:          descriptors,
+			}},
+			OriginalURIBaseIDs: baseIDs,
+			ColumnKind:         "unicodeCodePoints",
+			Results:            results,
+		}},
+	}
+}
+
+// sarifText returns the message of the diagnostic followed by its notes and help, which SARIF has no place for
+func sarifText(item *DiagnosticItem) string {
+	lines := []string{item.Msg}
+	for _, note := range item.Notes {
+		lines = append(lines, "note: "+note)
+	}
+	for _, help := range item.Help {
+		lines = append(lines, "help: "+help)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *sarifBuilder) location(pointer *DiagnosticPointer) (sarifLocation, bool) {
+	if pointer == nil {
+		return sarifLocation{}, false
+	}
+
+	path, err := pointer.Loc.Pathname()
+	if err != nil {
+		return sarifLocation{}, false
+	}
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: b.artifact(path),
+		Region:           b.region(path, pointer),
+	}}, true
+}
+
+func (b *sarifBuilder) artifact(path string) sarifArtifactLocation {
+	index, relative := b.roots.Find(path)
+	if index < 0 {
+		return sarifArtifactLocation{URI: fileURI(path)}
+	}
+
+	uri := url.URL{Path: relative}
+	return sarifArtifactLocation{URI: uri.EscapedPath(), URIBaseID: b.ids[index]}
+}
+
+// region returns the lines and columns the pointer covers.
+// The end is only known when the file can be read, since Length counts bytes.
+func (b *sarifBuilder) region(path string, pointer *DiagnosticPointer) *sarifRegion {
+	loc := pointer.Loc
+	region := &sarifRegion{StartLine: max(1, int(loc.Line)), StartColumn: max(1, int(loc.Column))}
+	if pointer.Length <= 0 {
+		return region
+	}
+
+	lines := b.lineIndex(path, loc)
+	if lines == nil {
+		return region
+	}
+
+	start := lines.Offset(region.StartLine, region.StartColumn)
+	end := start + pointer.Length
+	region.EndLine, _ = lines.Position(end)
+	region.EndColumn = lines.RuneColumn(end)
+
+	return region
+}
+
+// fix converts the edits of a fix to replacements, grouped by file in the order the files first appear.
+// Fixes of files that can't be read are left out, since their regions are unknown.
+func (b *sarifBuilder) fix(fix *Fix) (sarifFix, bool) {
+	result := sarifFix{Description: sarifMessage{Text: fix.Msg}}
+	changes := make(map[string]int)
+
+	for _, edit := range fix.Edits {
+		path, err := edit.Loc.Pathname()
+		if err != nil {
+			return sarifFix{}, false
+		}
+		if absolute, err := filepath.Abs(path); err == nil {
+			path = absolute
+		}
+
+		lines := b.lineIndex(path, edit.Loc)
+		if lines == nil {
+			return sarifFix{}, false
+		}
+
+		replacement := sarifReplacement{DeletedRegion: spanRegion(lines, edit.Span)}
+		if edit.NewText != "" {
+			replacement.InsertedContent = &sarifMessage{Text: edit.NewText}
+		}
+
+		index, ok := changes[path]
+		if !ok {
+			index = len(result.ArtifactChanges)
+			changes[path] = index
+			result.ArtifactChanges = append(result.ArtifactChanges, sarifArtifactChange{ArtifactLocation: b.artifact(path)})
+		}
+		result.ArtifactChanges[index].Replacements = append(result.ArtifactChanges[index].Replacements, replacement)
+	}
+
+	return result, len(result.ArtifactChanges) > 0
+}
+
+// spanRegion returns the lines and columns of a range of bytes
+func spanRegion(lines *tokens.LineIndex, span tokens.Span) sarifRegion {
+	startLine, _ := lines.Position(span.Start)
+	endLine, _ := lines.Position(span.End)
+	return sarifRegion{
+		StartLine:   startLine,
+		StartColumn: lines.RuneColumn(span.Start),
+		EndLine:     endLine,
+		EndColumn:   lines.RuneColumn(span.End),
+	}
+}
+
+// lineIndex returns the lines of the file at path, read from the file system of loc, or nil if it can't be read
+func (b *sarifBuilder) lineIndex(path string, loc tokens.Loc) *tokens.LineIndex {
+	if lines, ok := b.lines[path]; ok {
+		return lines
+	}
+
+	var lines *tokens.LineIndex
+	if content, err := loc.GetIdx().ReadFile(); err == nil {
+		content, _ = utils.SplitUTF8BOM(content)
+		lines = tokens.NewLineIndex(content)
+	}
+	b.lines[path] = lines
+
+	return lines
+}
+
+// fileURI returns the file URI of an absolute path
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths start with a drive letter
+		path = "/" + path
+	}
+
+	uri := url.URL{Scheme: "file", Path: path}
+	return uri.String()
+}
+
+func sarifLevel(s severity.Severity) string {
+	switch s {
+	case severity.Critical, severity.Error:
+		return "error"
+	case severity.Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
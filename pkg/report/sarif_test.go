@@ -0,0 +1,212 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// writeFile writes content to path under dir and returns the location of its start
+func writeFile(t *testing.T, dir string, path string, content string) tokens.Loc {
+	t.Helper()
+
+	path = filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *tokens.LocFromFileEntry(entry)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	modRoot := t.TempDir()
+	gameRoot := t.TempDir()
+
+	traitLoc := writeFile(t, modRoot, "common/traits/my traits.txt", "\uFEFFbrave = {\n\tcategory = имя_x\n}\n")
+	traitLoc.Line, traitLoc.Column = 2, 13
+	vanillaLoc := writeFile(t, gameRoot, "history/characters/a.txt", "a = {\n")
+	otherLoc := writeFile(t, t.TempDir(), "b.txt", "b")
+
+	items := []*DiagnosticItem{
+		{
+			Rule:     rules.Scoped(rules.ScopeTrait, rules.CheckUnexpectedValue),
+			Severity: rules.DefaultSeverity(rules.Scoped(rules.ScopeTrait, rules.CheckUnexpectedValue)),
+			Msg:      "expected one of personality, education",
+			Pointer:  &DiagnosticPointer{Loc: traitLoc, Length: len("имя_x")},
+		},
+		FromLoc(vanillaLoc, rules.ParseUnclosedBlock, "unclosed"),
+		FromLoc(otherLoc, rules.ParseSkippedSyntax, "skipped"),
+	}
+
+	var out bytes.Buffer
+	err := WriteSARIF(&out, items, SARIFRoot{ID: "MODROOT", Path: modRoot}, SARIFRoot{ID: "GAMEROOT", Path: gameRoot})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version = %q with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+		if rule.ShortDescription == nil || rule.FullDescription == nil || rule.DefaultConfiguration == nil {
+			t.Errorf("rule %q is not described", rule.ID)
+		}
+	}
+	if got, want := strings.Join(ruleIDs, " "), "parse/skipped-syntax parse/unclosed-block trait/unexpected-value"; got != want {
+		t.Errorf("rules = %q, want %q", got, want)
+	}
+
+	if !strings.HasPrefix(run.OriginalURIBaseIDs["MODROOT"].URI, "file:///") || !strings.HasSuffix(run.OriginalURIBaseIDs["MODROOT"].URI, "/") {
+		t.Errorf("MODROOT = %q", run.OriginalURIBaseIDs["MODROOT"].URI)
+	}
+
+	tests := []struct {
+		uri       string
+		baseID    string
+		level     string
+		region    sarifRegion
+		ruleIndex int
+	}{
+		{"common/traits/my%20traits.txt", "MODROOT", "error", sarifRegion{StartLine: 2, StartColumn: 13, EndLine: 2, EndColumn: 18}, 2},
+		{"history/characters/a.txt", "GAMEROOT", "error", sarifRegion{StartLine: 1, StartColumn: 1}, 1},
+		{"", "", "warning", sarifRegion{StartLine: 1, StartColumn: 1}, 0},
+	}
+
+	if len(run.Results) != len(tests) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(tests))
+	}
+	for i, tt := range tests {
+		result := run.Results[i]
+		location := result.Locations[0].PhysicalLocation
+
+		if tt.baseID != "" && location.ArtifactLocation.URI != tt.uri {
+			t.Errorf("result %d: uri = %q, want %q", i, location.ArtifactLocation.URI, tt.uri)
+		}
+		if tt.baseID == "" && !strings.HasPrefix(location.ArtifactLocation.URI, "file:///") {
+			t.Errorf("result %d: uri = %q, want an absolute file URI", i, location.ArtifactLocation.URI)
+		}
+		if location.ArtifactLocation.URIBaseID != tt.baseID {
+			t.Errorf("result %d: uriBaseId = %q, want %q", i, location.ArtifactLocation.URIBaseID, tt.baseID)
+		}
+		if result.Level != tt.level {
+			t.Errorf("result %d: level = %q, want %q", i, result.Level, tt.level)
+		}
+		if *location.Region != tt.region {
+			t.Errorf("result %d: region = %+v, want %+v", i, *location.Region, tt.region)
+		}
+		if result.RuleIndex == nil || *result.RuleIndex != tt.ruleIndex {
+			t.Errorf("result %d: ruleIndex = %v, want %d", i, result.RuleIndex, tt.ruleIndex)
+		}
+	}
+}
+
+func TestWriteSARIF_Related(t *testing.T) {
+	root := t.TempDir()
+	loc := writeFile(t, root, "common/traits/a.txt", "brave = {}\nbrave = {}\n")
+	first := loc
+	loc.Line = 2
+
+	item := FromLoc(loc, rules.ParseMissingValue, "defined twice").
+		WithRelated(&DiagnosticPointer{Loc: first, Length: len("brave")}, "first definition here").
+		WithNote("a note").
+		WithHelp("a suggestion").
+		WithFix("remove it", DeleteSpan(loc, tokens.Span{Start: 11, End: 21}))
+
+	var out bytes.Buffer
+	if err := WriteSARIF(&out, []*DiagnosticItem{item}, SARIFRoot{ID: "MODROOT", Path: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	result := log.Runs[0].Results[0]
+
+	if want := "defined twice\nnote: a note\nhelp: a suggestion"; result.Message.Text != want {
+		t.Errorf("message = %q, want %q", result.Message.Text, want)
+	}
+
+	if len(result.RelatedLocations) != 1 {
+		t.Fatalf("got %d related locations, want 1", len(result.RelatedLocations))
+	}
+	related := result.RelatedLocations[0]
+	if related.ID == nil || *related.ID != 1 || related.Message == nil || related.Message.Text != "first definition here" {
+		t.Errorf("related location = %+v", related)
+	}
+	want := sarifRegion{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 6}
+	if related.PhysicalLocation.ArtifactLocation.URI != "common/traits/a.txt" || *related.PhysicalLocation.Region != want {
+		t.Errorf("related location = %+v, region %+v", related.PhysicalLocation, *related.PhysicalLocation.Region)
+	}
+
+	if len(result.Fixes) != 1 || len(result.Fixes[0].ArtifactChanges) != 1 {
+		t.Fatalf("fixes = %+v, want one change", result.Fixes)
+	}
+	change := result.Fixes[0].ArtifactChanges[0]
+	deleted := sarifRegion{StartLine: 2, StartColumn: 1, EndLine: 2, EndColumn: 11}
+	if change.ArtifactLocation.URI != "common/traits/a.txt" || len(change.Replacements) != 1 || change.Replacements[0].DeletedRegion != deleted {
+		t.Errorf("change = %+v", change)
+	}
+}
+
+func TestWriteSARIF_NestedRoots(t *testing.T) {
+	game := t.TempDir()
+	loc := writeFile(t, game, "mod/a.txt", "a = b\n")
+
+	roots := []SARIFRoot{{ID: "GAMEROOT", Path: game}, {ID: "EMPTY"}, {ID: "MODROOT", Path: filepath.Join(game, "mod")}}
+	var out bytes.Buffer
+	if err := WriteSARIF(&out, []*DiagnosticItem{FromLoc(loc, rules.ParseMissingValue, "missing")}, roots...); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	// The innermost root wins, and the empty root has no base ID
+	artifact := log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation
+	if artifact.URI != "a.txt" || artifact.URIBaseID != "MODROOT" {
+		t.Errorf("artifact = %+v, want a.txt in MODROOT", artifact)
+	}
+	if baseIDs := log.Runs[0].OriginalURIBaseIDs; len(baseIDs) != 2 || baseIDs["EMPTY"].URI != "" {
+		t.Errorf("originalUriBaseIds = %+v, want GAMEROOT and MODROOT", baseIDs)
+	}
+}
+
+func TestWriteSARIF_Empty(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteSARIF(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Code scanning dashboards need the arrays even when there is nothing to report
+	for _, want := range []string{`"results": []`, `"rules": []`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %s:\n%s", want, out.String())
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package severity
 
+import "strings"
+
 type Severity int
 
 const (
@@ -23,3 +25,13 @@ func (s Severity) String() string {
 		return "Unknown"
 	}
 }
+
+// Parse returns the severity with the given name, in any case
+func Parse(name string) (Severity, bool) {
+	for _, s := range []Severity{Info, Warning, Error, Critical} {
+		if strings.EqualFold(name, s.String()) {
+			return s, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/unLomTrois/gock3/pkg/cache"
+)
+
+// Sink receives the diagnostics added to an ErrorManager.
+// Sinks may be shared by several managers, so they must be safe for concurrent use.
+type Sink interface {
+	Report(item *DiagnosticItem)
+}
+
+// MemorySink keeps diagnostics in memory
+type MemorySink struct {
+	mu    sync.Mutex
+	items []*DiagnosticItem
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Report(item *DiagnosticItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = append(s.items, item)
+}
+
+// Items returns the diagnostics received so far
+func (s *MemorySink) Items() []*DiagnosticItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]*DiagnosticItem, len(s.items))
+	copy(items, s.items)
+	return items
+}
+
+// TerminalSink prints every diagnostic with the source it points at, see Renderer.
+// Colors are used when the output is a terminal that supports them.
+type TerminalSink struct {
+	mu       sync.Mutex
+	out      io.Writer
+	renderer *Renderer
+}
+
+func NewTerminalSink(out io.Writer) *TerminalSink {
+	return &TerminalSink{
+		out:      out,
+		renderer: NewRenderer(cache.NewFileCache(), !color.NoColor),
+	}
+}
+
+func (s *TerminalSink) Report(item *DiagnosticItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintln(s.out, s.renderer.Render(item))
+}
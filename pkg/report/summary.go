@@ -0,0 +1,105 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+// Summary counts diagnostics by severity and by rule
+type Summary struct {
+	Total      int
+	BySeverity map[severity.Severity]int
+	ByRule     map[rules.ID]int
+}
+
+// Summarize counts the diagnostics
+func Summarize(items []*DiagnosticItem) *Summary {
+	summary := &Summary{
+		Total:      len(items),
+		BySeverity: make(map[severity.Severity]int),
+		ByRule:     make(map[rules.ID]int),
+	}
+	for _, item := range items {
+		summary.BySeverity[item.Severity]++
+		summary.ByRule[item.Rule]++
+	}
+	return summary
+}
+
+// AtLeast returns the number of diagnostics of the severity or a higher one
+func (s *Summary) AtLeast(threshold severity.Severity) int {
+	count := 0
+	for level, n := range s.BySeverity {
+		if level >= threshold {
+			count += n
+		}
+	}
+	return count
+}
+
+// severityNames are the plural names of the severities, from the highest
+var severityNames = []struct {
+	severity severity.Severity
+	one      string
+	many     string
+}{
+	{severity.Critical, "critical", "critical"},
+	{severity.Error, "error", "errors"},
+	{severity.Warning, "warning", "warnings"},
+	{severity.Info, "info", "info"},
+}
+
+// Write writes the counts per severity on one line, followed by the counts per rule sorted by ID:
+//
+//	Found 3 problems: 1 error, 2 warnings
+//	  parse/missing-value  1
+//	  trait/duplicate      2
+func (s *Summary) Write(w io.Writer) error {
+	if s.Total == 0 {
+		_, err := fmt.Fprintln(w, "No problems found")
+		return err
+	}
+
+	var counts []string
+	for _, name := range severityNames {
+		n := s.BySeverity[name.severity]
+		switch {
+		case n == 1:
+			counts = append(counts, "1 "+name.one)
+		case n > 1:
+			counts = append(counts, fmt.Sprintf("%d %s", n, name.many))
+		}
+	}
+
+	problems := "problems"
+	if s.Total == 1 {
+		problems = "problem"
+	}
+	if _, err := fmt.Fprintf(w, "Found %d %s: %s\n", s.Total, problems, strings.Join(counts, ", ")); err != nil {
+		return err
+	}
+
+	ids := make([]rules.ID, 0, len(s.ByRule))
+	for id := range s.ByRule {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, id := range ids {
+		name := string(id)
+		if name == "" {
+			name = "(no rule)"
+		}
+		fmt.Fprintf(table, "  %s\t%d\n", name, s.ByRule[id])
+	}
+	return table.Flush()
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+	"github.com/unLomTrois/gock3/pkg/report/severity"
+)
+
+func TestSummary(t *testing.T) {
+	item := func(rule rules.ID, s severity.Severity) *DiagnosticItem {
+		return &DiagnosticItem{Rule: rule, Severity: s, Msg: "problem"}
+	}
+
+	tests := []struct {
+		name    string
+		items   []*DiagnosticItem
+		want    string
+		atLeast map[severity.Severity]int
+	}{
+		{
+			name:    "nothing",
+			items:   nil,
+			want:    "No problems found\n",
+			atLeast: map[severity.Severity]int{severity.Info: 0},
+		},
+		{
+			name:  "one",
+			items: []*DiagnosticItem{item(rules.TraitDuplicate, severity.Warning)},
+			want: "Found 1 problem: 1 warning\n" +
+				"  trait/duplicate  1\n",
+			atLeast: map[severity.Severity]int{severity.Warning: 1, severity.Error: 0},
+		},
+		{
+			name: "several",
+			items: []*DiagnosticItem{
+				item(rules.TraitDuplicate, severity.Warning),
+				item(rules.ParseMissingValue, severity.Error),
+				item(rules.TraitDuplicate, severity.Warning),
+				item(rules.LexUnexpectedCharacter, severity.Critical),
+				item("", severity.Info),
+			},
+			want: "Found 5 problems: 1 critical, 1 error, 2 warnings, 1 info\n" +
+				"  (no rule)                 1\n" +
+				"  lex/unexpected-character  1\n" +
+				"  parse/missing-value       1\n" +
+				"  trait/duplicate           2\n",
+			atLeast: map[severity.Severity]int{severity.Info: 5, severity.Warning: 4, severity.Error: 2, severity.Critical: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := Summarize(tt.items)
+
+			var out strings.Builder
+			if err := summary.Write(&out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+
+			for threshold, want := range tt.atLeast {
+				if got := summary.AtLeast(threshold); got != want {
+					t.Errorf("AtLeast(%v) = %d, want %d", threshold, got, want)
+				}
+			}
+		})
+	}
+}
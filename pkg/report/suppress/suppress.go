@@ -0,0 +1,155 @@
+// Package suppress silences diagnostics with directives in the comments of the files they point at:
+//
+//	# gock3:ignore-file character/duplicate
+//
+//	brave = {
+//		# gock3:ignore trait/out-of-range
+//		random_creation_weight = 1000
+//		birth = 2 # gock3:ignore trait/out-of-range
+//	}
+//
+// An ignore directive alone on its line applies to the next line, and one after a field to its own line.
+// An ignore-file directive applies to the whole file, and belongs at its top.
+// Rules are given by ID, or by category as trait or trait/*. A directive without rules applies to every rule.
+package suppress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// Names of the directives
+const (
+	Ignore     = "ignore"
+	IgnoreFile = "ignore-file"
+)
+
+// suppression is an ignore or ignore-file directive and what it has suppressed so far
+type suppression struct {
+	directive *ast.Directive
+	file      bool
+	// line is the line the directive applies to, unless it applies to the file
+	line uint32
+	// used tells for every rule of the directive whether it suppressed a diagnostic
+	used []bool
+	// usedAny is whether the directive suppressed a diagnostic
+	usedAny bool
+}
+
+// matches reports whether the suppression applies to the diagnostic, and marks the rules that do as used
+func (s *suppression) matches(item *report.DiagnosticItem) bool {
+	loc := item.Pointer.Loc
+	if !s.directive.Comment.Loc.SameFile(loc) || (!s.file && loc.Line != s.line) {
+		return false
+	}
+
+	if len(s.directive.Args) == 0 {
+		s.usedAny = true
+		return true
+	}
+
+	matched := false
+	for i, pattern := range s.directive.Args {
+		if item.Rule.Matches(pattern) {
+			s.used[i] = true
+			matched = true
+		}
+	}
+	s.usedAny = s.usedAny || matched
+
+	return matched
+}
+
+// Apply returns the diagnostics that no directive suppresses, in their order,
+// followed by the diagnostics of the directives that suppress nothing or are not understood.
+func Apply(items []*report.DiagnosticItem, directives []*ast.Directive) []*report.DiagnosticItem {
+	var problems []*report.DiagnosticItem
+	var suppressions []*suppression
+	byFile := make(map[files.PathTableIndex][]*suppression)
+
+	for _, directive := range directives {
+		s := &suppression{directive: directive, used: make([]bool, len(directive.Args))}
+
+		switch directive.Name {
+		case Ignore:
+			s.line = directive.Comment.Loc.Line
+			if directive.OwnLine {
+				s.line++
+			}
+		case IgnoreFile:
+			s.file = true
+		default:
+			problems = append(problems, report.FromToken(directive.Comment, rules.SuppressionUnknownDirective,
+				fmt.Sprintf("unknown directive %q, expected %s or %s", "gock3:"+directive.Name, Ignore, IgnoreFile)))
+			continue
+		}
+
+		index := directive.Comment.Loc.GetIdx()
+		byFile[index] = append(byFile[index], s)
+		suppressions = append(suppressions, s)
+	}
+
+	kept := make([]*report.DiagnosticItem, 0, len(items))
+	for _, item := range items {
+		suppressed := false
+		if item.Pointer != nil {
+			// Every matching directive counts as used, not only the first one
+			for _, s := range byFile[item.Pointer.Loc.GetIdx()] {
+				if s.matches(item) {
+					suppressed = true
+				}
+			}
+		}
+
+		if !suppressed {
+			kept = append(kept, item)
+		}
+	}
+
+	for _, s := range suppressions {
+		problems = append(problems, s.unused()...)
+	}
+
+	return append(kept, problems...)
+}
+
+// unused returns a diagnostic for every rule of the directive that suppressed nothing,
+// or a single one if the whole directive suppressed nothing
+func (s *suppression) unused() []*report.DiagnosticItem {
+	comment := s.directive.Comment
+
+	if !s.usedAny {
+		msg := "unused suppression"
+		if len(s.directive.Args) > 0 {
+			msg = "unused suppression of " + strings.Join(s.directive.Args, ", ")
+		}
+		item := report.FromToken(comment, rules.SuppressionUnused, msg).
+			WithFix("remove the comment", report.DeleteSpan(comment.Loc, comment.Span))
+		addUnknownRuleHelp(item, s.directive.Args)
+		return []*report.DiagnosticItem{item}
+	}
+
+	var result []*report.DiagnosticItem
+	for i, pattern := range s.directive.Args {
+		if !s.used[i] {
+			item := report.FromToken(comment, rules.SuppressionUnused, "unused suppression of "+pattern)
+			addUnknownRuleHelp(item, []string{pattern})
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// addUnknownRuleHelp points out the patterns that no rule matches, which are probably misspelled
+func addUnknownRuleHelp(item *report.DiagnosticItem, patterns []string) {
+	for _, pattern := range patterns {
+		if !rules.Known(pattern) {
+			item.WithHelp(fmt.Sprintf("no rule has the ID or category %q, see `gock3 rules`", pattern))
+		}
+	}
+}
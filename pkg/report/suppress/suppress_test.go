@@ -0,0 +1,157 @@
+package suppress
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/internal/app/pdxfile"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// parse parses the text as a file of the mod and returns its directives and the location of its start
+func parse(t *testing.T, text string) ([]*ast.Directive, tokens.Loc) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := files.OpenFileEntry(nil, nil, path, files.Mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, _, err := pdxfile.ParseFile(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree.Directives, *tokens.LocFromFileEntry(entry)
+}
+
+func TestApply(t *testing.T) {
+	outOfRange := rules.Scoped(rules.ScopeTrait, rules.CheckOutOfRange)
+	banned := rules.Scoped(rules.ScopeTrait, rules.CheckBannedField)
+
+	type diagnostic struct {
+		line uint32
+		rule rules.ID
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		items []diagnostic
+		// want are the lines and rules of the diagnostics left, with the unused suppressions last
+		want []diagnostic
+	}{
+		{
+			name:  "line before",
+			text:  "a = {\n\t# gock3:ignore trait/out-of-range\n\tb = 2\n}\n",
+			items: []diagnostic{{3, outOfRange}, {3, banned}},
+			want:  []diagnostic{{3, banned}},
+		},
+		{
+			name:  "same line",
+			text:  "a = {\n\tb = 2 # gock3:ignore trait/out-of-range\n\tc = 3\n}\n",
+			items: []diagnostic{{2, outOfRange}, {3, outOfRange}},
+			want:  []diagnostic{{3, outOfRange}},
+		},
+		{
+			name:  "category",
+			text:  "a = {\n\tb = 2 # gock3:ignore trait/*\n\tc = 3 # gock3:ignore trait\n}\n",
+			items: []diagnostic{{2, outOfRange}, {3, banned}},
+			want:  nil,
+		},
+		{
+			name:  "several rules",
+			text:  "a = {\n\tb = 2 # gock3:ignore trait/out-of-range, trait/banned-field\n}\n",
+			items: []diagnostic{{2, outOfRange}, {2, banned}},
+			want:  nil,
+		},
+		{
+			name:  "every rule",
+			text:  "a = b # gock3:ignore\n",
+			items: []diagnostic{{1, outOfRange}, {1, rules.ParseMissingValue}},
+			want:  nil,
+		},
+		{
+			name:  "whole file",
+			text:  "# gock3:ignore-file trait/out-of-range\na = {\n\tb = 2\n}\n",
+			items: []diagnostic{{3, outOfRange}, {3, banned}},
+			want:  []diagnostic{{3, banned}},
+		},
+		{
+			name:  "unused",
+			text:  "# gock3:ignore trait/range\na = b\n",
+			items: []diagnostic{{2, outOfRange}},
+			want:  []diagnostic{{2, outOfRange}, {1, rules.SuppressionUnused}},
+		},
+		{
+			name:  "partly unused",
+			text:  "a = b # gock3:ignore trait/out-of-range trait/banned-field\n",
+			items: []diagnostic{{1, outOfRange}},
+			want:  []diagnostic{{1, rules.SuppressionUnused}},
+		},
+		{
+			name:  "unknown directive",
+			text:  "a = b # gock3:ignroe trait/out-of-range\n",
+			items: []diagnostic{{1, outOfRange}},
+			want:  []diagnostic{{1, outOfRange}, {1, rules.SuppressionUnknownDirective}},
+		},
+		{
+			name:  "not a directive",
+			text:  "# ignore trait/out-of-range\na = b\n",
+			items: []diagnostic{{2, outOfRange}},
+			want:  []diagnostic{{2, outOfRange}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directives, loc := parse(t, tt.text)
+
+			var items []*report.DiagnosticItem
+			for _, d := range tt.items {
+				loc.Line = d.line
+				items = append(items, report.FromLoc(loc, d.rule, "problem"))
+			}
+
+			var got []diagnostic
+			for _, item := range Apply(items, directives) {
+				got = append(got, diagnostic{item.Pointer.Loc.Line, item.Rule})
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply_OtherFile(t *testing.T) {
+	directives, _ := parse(t, "# gock3:ignore-file\n")
+	_, other := parse(t, "a = b\n")
+
+	got := Apply([]*report.DiagnosticItem{report.FromLoc(other, rules.ParseMissingValue, "problem")}, directives)
+	if len(got) != 2 || got[0].Rule != rules.ParseMissingValue || got[1].Rule != rules.SuppressionUnused {
+		t.Errorf("Apply() = %v, want the diagnostic of the other file and the unused suppression", got)
+	}
+}
+
+func TestApply_UnusedFix(t *testing.T) {
+	directives, _ := parse(t, "a = b\n# gock3:ignore trait/range\nc = d\n")
+
+	got := Apply(nil, directives)
+	if len(got) != 1 || len(got[0].Fixes) != 1 || len(got[0].Help) != 1 {
+		t.Fatalf("Apply() = %v, want an unused suppression with a fix and a help", got)
+	}
+	if span := got[0].Fixes[0].Edits[0].Span; span != (tokens.Span{Start: 6, End: 32}) {
+		t.Errorf("fix deletes %v, want the comment", span)
+	}
+}
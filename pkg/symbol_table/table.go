@@ -1,12 +1,16 @@
 package symboltable
 
 import (
+	"fmt"
+
 	"github.com/unLomTrois/gock3/pkg/entity"
+	"github.com/unLomTrois/gock3/pkg/report"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
 type SymbolTableInterface interface {
-	AddEntity(entity entity.Entity)
-	AddEntities(entities []entity.Entity)
+	AddEntity(entity entity.Entity) *report.DiagnosticItem
+	AddEntities(entities []entity.Entity) []*report.DiagnosticItem
 	Get(name string) entity.Entity
 	Contains(name string) bool
 }
@@ -20,7 +24,17 @@ func NewSymbolTable() *SymbolTable {
 	}
 }
 
-func (st *SymbolTable) AddEntity(item entity.Entity) {
+// duplicateRules are the rules of entities defined twice, by kind
+var duplicateRules = map[entity.EntityKind]rules.ID{
+	entity.KindTrait:     rules.TraitDuplicate,
+	entity.KindCharacter: rules.CharacterDuplicate,
+}
+
+// AddEntity stores the entity under its kind and name.
+// An entity of a mod overrides the one of the game or of an earlier mod with the same name,
+// and is never overridden by them, whatever order they are added in.
+// Two entities of the game or of the same mod are a duplicate: the last one is kept and a diagnostic is returned.
+func (st *SymbolTable) AddEntity(item entity.Entity) *report.DiagnosticItem {
 	kind := item.GetKind()
 
 	if _, exists := st.store[kind]; !exists {
@@ -28,13 +42,41 @@ func (st *SymbolTable) AddEntity(item entity.Entity) {
 	}
 
 	name := item.Name()
+	existing, found := st.store[kind][name]
+	if !found {
+		st.store[kind][name] = item
+		return nil
+	}
+
+	existingOrder, order := existing.Key().Loc.Source().Order, item.Key().Loc.Source().Order
+	if existingOrder != order {
+		if order > existingOrder {
+			st.store[kind][name] = item
+		}
+		return nil
+	}
+
 	st.store[kind][name] = item
+
+	rule, ok := duplicateRules[kind]
+	if !ok {
+		return nil
+	}
+	return report.FromToken(item.Key(), rule, fmt.Sprintf("%s %s is defined twice", kind, name)).
+		WithEntity(name).
+		WithRelated(report.TokenPointer(existing.Key()), "first definition here").
+		WithNote("only the last definition is used")
 }
 
-func (st *SymbolTable) AddEntities(entities []entity.Entity) {
+// AddEntities adds every entity and returns the diagnostics of the duplicates
+func (st *SymbolTable) AddEntities(entities []entity.Entity) []*report.DiagnosticItem {
+	var diagnostics []*report.DiagnosticItem
 	for _, entity := range entities {
-		st.AddEntity(entity)
+		if diagnostic := st.AddEntity(entity); diagnostic != nil {
+			diagnostics = append(diagnostics, diagnostic)
+		}
 	}
+	return diagnostics
 }
 
 func (st *SymbolTable) Get(kind entity.EntityKind, name string) (entity.Entity, bool) {
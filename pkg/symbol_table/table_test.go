@@ -0,0 +1,137 @@
+package symboltable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/unLomTrois/gock3/internal/app/files"
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/pkg/entity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+type testEntity struct {
+	kind entity.EntityKind
+	key  *tokens.Token
+}
+
+func (e *testEntity) Name() string               { return e.key.Value }
+func (e *testEntity) Location() string           { return "" }
+func (e *testEntity) GetKind() entity.EntityKind { return e.kind }
+func (e *testEntity) Key() *tokens.Token         { return e.key }
+
+// newTestEntity returns an entity defined on the given line of a file of the given origin
+func newTestEntity(t *testing.T, kind entity.EntityKind, name string, origin files.FileKind, line uint32) *testEntity {
+	t.Helper()
+
+	fsys := fstest.MapFS{"test.txt": {Data: []byte(name + " = {}\n")}}
+	entry, err := files.OpenFileEntry(nil, fsys, "test.txt", origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc := *tokens.LocFromFileEntry(entry)
+	loc.Line = line
+	return &testEntity{kind: kind, key: tokens.New(name, tokens.WORD, loc)}
+}
+
+func TestSymbolTable_AddEntity(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  files.FileKind
+		second files.FileKind
+		// kept is the line of the entity left in the table
+		kept uint32
+		rule rules.ID
+	}{
+		{"duplicate in the mod", files.Mod, files.Mod, 2, rules.TraitDuplicate},
+		{"duplicate in the game", files.Vanilla, files.Vanilla, 2, rules.TraitDuplicate},
+		{"mod overrides the game", files.Vanilla, files.Mod, 2, ""},
+		{"game doesn't override the mod", files.Mod, files.Vanilla, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewSymbolTable()
+			first := newTestEntity(t, entity.KindTrait, "brave", tt.first, 1)
+			second := newTestEntity(t, entity.KindTrait, "brave", tt.second, 2)
+
+			if diagnostic := table.AddEntity(first); diagnostic != nil {
+				t.Fatalf("first AddEntity() = %v, want nil", diagnostic)
+			}
+
+			diagnostic := table.AddEntity(second)
+			switch {
+			case tt.rule == "" && diagnostic != nil:
+				t.Errorf("AddEntity() = %v, want nil", diagnostic)
+			case tt.rule != "" && diagnostic == nil:
+				t.Errorf("AddEntity() = nil, want %s", tt.rule)
+			case tt.rule != "":
+				if diagnostic.Rule != tt.rule || diagnostic.Msg != "trait brave is defined twice" {
+					t.Errorf("AddEntity() = %v", diagnostic)
+				}
+				if len(diagnostic.Related) != 1 || diagnostic.Related[0].Pointer.Loc.Line != 1 {
+					t.Errorf("related locations = %+v, want the first definition", diagnostic.Related)
+				}
+			}
+
+			kept, _ := table.Get(entity.KindTrait, "brave")
+			if line := kept.Key().Loc.Line; line != tt.kept {
+				t.Errorf("kept the entity of line %d, want %d", line, tt.kept)
+			}
+			if table.Len() != 1 {
+				t.Errorf("Len() = %d, want 1", table.Len())
+			}
+		})
+	}
+}
+
+func TestSymbolTable_AddEntities(t *testing.T) {
+	table := NewSymbolTable()
+
+	diagnostics := table.AddEntities([]entity.Entity{
+		newTestEntity(t, entity.KindTrait, "brave", files.Mod, 1),
+		newTestEntity(t, entity.KindCharacter, "brave", files.Mod, 2),
+		newTestEntity(t, entity.KindCharacter, "brave", files.Mod, 3),
+	})
+
+	if len(diagnostics) != 1 || diagnostics[0].Rule != rules.CharacterDuplicate {
+		t.Errorf("AddEntities() = %v, want a single %s", diagnostics, rules.CharacterDuplicate)
+	}
+}
+
+func TestSymbolTable_AddEntity_Playset(t *testing.T) {
+	game, first, second := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, root := range []string{first, second} {
+		if err := os.WriteFile(filepath.Join(root, "traits.txt"), []byte("brave = {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := files.ScanPlayset(game, files.NewModLoader("First", first, nil), files.NewModLoader("Second", second, nil))
+	if err != nil || len(entries) != 1 || entries[0].Replaces() == nil {
+		t.Fatalf("ScanPlayset() = %v, %v, want a file of the second mod replacing the first", entries, err)
+	}
+
+	newEntity := func(entry *files.FileEntry) *testEntity {
+		return &testEntity{kind: entity.KindTrait, key: tokens.New("brave", tokens.WORD, *tokens.LocFromFileEntry(entry))}
+	}
+	later, earlier := newEntity(entries[0]), newEntity(entries[0].Replaces())
+
+	// The later mod wins whatever the order the entities are added in
+	for _, order := range [][]*testEntity{{earlier, later}, {later, earlier}} {
+		table := NewSymbolTable()
+		for _, e := range order {
+			if diagnostic := table.AddEntity(e); diagnostic != nil {
+				t.Errorf("AddEntity() = %v, want nil", diagnostic)
+			}
+		}
+
+		kept, _ := table.Get(entity.KindTrait, "brave")
+		if source := kept.Key().Loc.Source(); source.Name != "Second" {
+			t.Errorf("kept the entity of %+v, want the second mod", source)
+		}
+	}
+}
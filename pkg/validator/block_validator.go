@@ -2,17 +2,19 @@ package validator
 
 import (
 	"fmt"
+	"strings"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
 type BlockValidator struct {
 	block  *ast.FieldBlock
 	fields map[string]*ast.Field
+	scope  rules.Scope
 	*report.ErrorManager
 }
 
@@ -22,10 +24,11 @@ type BlockValidator struct {
 //
 // Parameters:
 //   - block: A pointer to the FieldBlock containing fields to validate.
+//   - scope: The kind of entity the block defines, which the rules of the reported errors belong to.
 //
 // Returns:
 //   - A pointer to the newly created BlockValidator instance.
-func NewBlockValidator(block *ast.FieldBlock) *BlockValidator {
+func NewBlockValidator(block *ast.FieldBlock, scope rules.Scope) *BlockValidator {
 	fields := make(map[string]*ast.Field, len(block.Values))
 	for _, field := range block.Values {
 		fields[field.Key.Value] = field
@@ -34,10 +37,16 @@ func NewBlockValidator(block *ast.FieldBlock) *BlockValidator {
 	return &BlockValidator{
 		block:        block,
 		fields:       fields,
+		scope:        scope,
 		ErrorManager: report.NewErrorManager(),
 	}
 }
 
+// rule returns the ID of a check in the scope of the validator
+func (bv *BlockValidator) rule(check rules.Check) rules.ID {
+	return rules.Scoped(bv.scope, check)
+}
+
 // ExpectBlock checks that there is a field with a certain key whose value is a block.
 func (bv *BlockValidator) ExpectBlock(key string) ast.Block {
 	field, exists := bv.fields[key]
@@ -47,7 +56,7 @@ func (bv *BlockValidator) ExpectBlock(key string) ast.Block {
 
 	block, ok := field.Value.(ast.Block)
 	if !ok {
-		err := report.FromToken(field.Key, severity.Error, "expected a block, not a token")
+		err := report.FromToken(field.Key, bv.rule(rules.CheckExpectedBlock), "expected a block, not a token")
 		bv.AddError(err)
 		return nil
 	}
@@ -64,7 +73,7 @@ func (bv *BlockValidator) ExpectToken(key string) *tokens.Token {
 
 	token, ok := field.Value.(*tokens.Token)
 	if !ok {
-		err := report.FromLoc(field.Key.Loc, severity.Error, "expected a token, not a block")
+		err := report.FromLoc(field.Key.Loc, bv.rule(rules.CheckExpectedToken), "expected a token, not a block")
 		bv.AddError(err)
 		return nil
 	}
@@ -90,7 +99,7 @@ func (bv *BlockValidator) ExpectValueToBeInSet(key string, set mapset.Set[string
 
 	ok := set.Contains(token.Value)
 	if !ok {
-		err := report.FromToken(token, severity.Error, fmt.Sprintf("expected one of %v", set))
+		err := report.FromToken(token, bv.rule(rules.CheckUnexpectedValue), fmt.Sprintf("expected one of %v", set))
 		bv.AddError(err)
 	}
 
@@ -106,7 +115,7 @@ func (bv *BlockValidator) ExpectType(key string, tt tokens.TokenType) (*tokens.T
 
 	ok := token.IsType(tt)
 	if !ok {
-		err := report.FromToken(token, severity.Error, fmt.Sprintf("expected type %s", tt))
+		err := report.FromToken(token, bv.rule(rules.CheckWrongType), fmt.Sprintf("expected type %s", tt))
 		bv.AddError(err)
 	}
 
@@ -129,7 +138,7 @@ func (bv *BlockValidator) ExpectNumber(key string) bool {
 
 	ok := token.IsType(tokens.NUMBER)
 	if !ok {
-		err := report.FromToken(token, severity.Error, "expected a number")
+		err := report.FromToken(token, bv.rule(rules.CheckWrongType), "expected a number")
 		bv.AddError(err)
 	}
 
@@ -144,7 +153,11 @@ func (bv *BlockValidator) ExpectString(key string) bool {
 
 	ok := token.IsType(tokens.QUOTED_STRING)
 	if !ok {
-		err := report.FromToken(token, severity.Error, "expected string")
+		err := report.FromToken(token, bv.rule(rules.CheckWrongType), "expected string")
+		// Quoted strings have no escapes, so a value with a quote or a backslash can't be quoted as is
+		if !strings.ContainsAny(token.Value, `"\`) {
+			err = err.WithFix("quote the value", report.ReplaceToken(token, `"`+token.Value+`"`))
+		}
 		bv.AddError(err)
 	}
 
@@ -154,7 +167,7 @@ func (bv *BlockValidator) ExpectString(key string) bool {
 func (bv *BlockValidator) RequireField(key string) bool {
 	_, exists := bv.fields[key]
 	if !exists {
-		err := report.FromBlock(bv.block, severity.Error, fmt.Sprintf("required field '%s' is missing", key))
+		err := report.FromBlock(bv.block, bv.rule(rules.CheckMissingField), fmt.Sprintf("required field '%s' is missing", key))
 		bv.AddError(err)
 	}
 	return exists
@@ -169,12 +182,12 @@ func (bv *BlockValidator) ExpectNumberInRange(key string, min float64, max float
 
 	value, err := token.FloatValue()
 	if err != nil {
-		err := report.FromToken(token, severity.Error, "expected a number")
+		err := report.FromToken(token, bv.rule(rules.CheckWrongType), "expected a number")
 		bv.AddError(err)
 		return false
 	}
 	if value < min || value > max {
-		err := report.FromToken(token, severity.Error, fmt.Sprintf("expected number in range [%f, %f]", min, max))
+		err := report.FromToken(token, bv.rule(rules.CheckOutOfRange), fmt.Sprintf("expected number in range [%f, %f]", min, max))
 		bv.AddError(err)
 		return false
 	}
@@ -182,11 +195,12 @@ func (bv *BlockValidator) ExpectNumberInRange(key string, min float64, max float
 	return true
 }
 
-// ban field
+// BanField reports the field with the key if there is one, with a fix that removes it
 func (bv *BlockValidator) BanField(key string, because string) {
-	_, exists := bv.fields[key]
+	field, exists := bv.fields[key]
 	if exists {
-		err := report.FromBlock(bv.block, severity.Error, fmt.Sprintf("field '%s' is not allowed, because %s", key, because))
+		err := report.FromToken(field.Key, bv.rule(rules.CheckBannedField), fmt.Sprintf("field '%s' is not allowed, because %s", key, because)).
+			WithFix(fmt.Sprintf("remove '%s'", key), report.DeleteSpan(field.Key.Loc, field.Span()))
 		bv.AddError(err)
 	}
 }
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
+	"github.com/unLomTrois/gock3/internal/app/parser/ast"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
+)
+
+// token returns a token as if it was read at the start of a file, which fixes can edit
+func token(value string, tokenType tokens.TokenType) *tokens.Token {
+	t := tokens.New(value, tokenType, tokens.Loc{})
+	t.Span = tokens.Span{Start: 0, End: len(value)}
+	return t
+}
+
+func TestBlockValidator_ExpectString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *tokens.Token
+		// fix is the replacement offered, empty for none
+		fix string
+	}{
+		{"quoted string", token(`"1.0"`, tokens.QUOTED_STRING), ""},
+		{"word", token("my_mod", tokens.WORD), `"my_mod"`},
+		{"number", token("1.0", tokens.NUMBER), `"1.0"`},
+		{"backslash", token(`mod\path`, tokens.WORD), ""},
+		{"quote", token(`it"s`, tokens.WORD), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &ast.FieldBlock{Values: []*ast.Field{{Key: token("name", tokens.WORD), Value: tt.value}}}
+			bv := NewBlockValidator(block, rules.ScopeMod)
+
+			ok := bv.ExpectString("name")
+			if ok != tt.value.IsType(tokens.QUOTED_STRING) {
+				t.Errorf("ExpectString() = %v", ok)
+			}
+			if ok {
+				return
+			}
+
+			errs := bv.Errors()
+			if len(errs) != 1 {
+				t.Fatalf("Errors() = %v, want a single diagnostic", errs)
+			}
+			var fix string
+			if len(errs[0].Fixes) > 0 {
+				fix = errs[0].Fixes[0].Edits[0].NewText
+			}
+			if fix != tt.fix {
+				t.Errorf("fix = %q, want %q", fix, tt.fix)
+			}
+		})
+	}
+}
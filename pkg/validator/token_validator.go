@@ -6,17 +6,19 @@ import (
 	"github.com/unLomTrois/gock3/internal/app/lexer/tokens"
 	"github.com/unLomTrois/gock3/internal/app/parser/ast"
 	"github.com/unLomTrois/gock3/pkg/report"
-	"github.com/unLomTrois/gock3/pkg/report/severity"
+	"github.com/unLomTrois/gock3/pkg/report/rules"
 )
 
 type TokenValidator struct {
 	Block  *ast.TokenBlock
+	scope  rules.Scope
 	errors []*report.DiagnosticItem
 }
 
-func NewTokenValidator(block *ast.TokenBlock) *TokenValidator {
+func NewTokenValidator(block *ast.TokenBlock, scope rules.Scope) *TokenValidator {
 	return &TokenValidator{
 		Block: block,
+		scope: scope,
 	}
 }
 
@@ -27,7 +29,7 @@ func (tv *TokenValidator) ExpectAllTokensToBe(ttype tokens.TokenType) bool {
 	for _, token := range tv.Block.Values {
 		if !token.IsType(ttype) {
 			ok = false
-			err := report.FromToken(token, severity.Error, fmt.Sprintf("expected %v", ttype))
+			err := report.FromToken(token, rules.Scoped(tv.scope, rules.CheckWrongType), fmt.Sprintf("expected %v", ttype))
 			tv.errors = append(tv.errors, err)
 		}
 	}